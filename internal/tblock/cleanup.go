@@ -0,0 +1,82 @@
+package tblock
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/havrydotdev/tblock-launcher/internal/utils"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+// freeDiskSpace shows files left over from old versions and deletes them once player confirms
+func (l *Launcher) freeDiskSpace() {
+	d := downloader.New(l.cfg).WithLogger(l.log)
+
+	go func() {
+		report, err := diskUsage(d, l.cfg.GameDir)
+
+		fyne.Do(func() {
+			if err != nil {
+				l.showError(err)
+				return
+			}
+
+			orphans := report.Orphans()
+			if len(orphans) == 0 {
+				dialog.ShowInformation(lang.L("Free up disk space"), lang.L("Nothing to clean up"), l.w)
+				return
+			}
+
+			var summary []string
+			for _, c := range report.Categories {
+				summary = append(summary, fmt.Sprintf("%s: %s / %s", c.Category,
+					formatBytes(float64(c.Reclaimable)), formatBytes(float64(c.Total))))
+			}
+
+			// dry run, player sees exactly what goes away
+			files := widget.NewList(
+				func() int { return len(orphans) },
+				func() fyne.CanvasObject { return widget.NewLabel("") },
+				func(i widget.ListItemID, o fyne.CanvasObject) { o.(*widget.Label).SetText(orphans[i]) },
+			)
+
+			content := container.NewBorder(widget.NewLabel(strings.Join(summary, "\n")), nil, nil, nil, files)
+
+			confirm := dialog.NewCustomConfirm(lang.L("Free up disk space"), lang.L("Delete"), lang.L("Cancel"), content, func(ok bool) {
+				if !ok {
+					return
+				}
+
+				go func() {
+					freed, err := d.CollectGarbage(report)
+					fyne.Do(func() {
+						if err != nil {
+							l.showError(err)
+							return
+						}
+
+						dialog.ShowInformation(lang.L("Free up disk space"),
+							fmt.Sprintf(lang.L("Freed %s"), formatBytes(float64(freed))), l.w)
+					})
+				}()
+			}, l.w)
+			confirm.Resize(fyne.NewSize(600, 400))
+			confirm.Show()
+		})
+	}()
+}
+
+// diskUsage keeps files other instances' versions refer to
+func diskUsage(d *downloader.Downloader, gameDir string) (*downloader.UsageReport, error) {
+	others, err := utils.OtherGameDirs(gameDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.DiskUsage(others...)
+}
@@ -0,0 +1,260 @@
+package tblock
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/internal/utils"
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/mods"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+// RunVerify checks installation without starting the gui and optionally repairs it.
+// deep rehashes every file instead of trusting the hash cache.
+// Returns error if broken files are left behind
+func RunVerify(repair, deep bool) error {
+	d, err := cliDownloader()
+	if err != nil {
+		return err
+	}
+
+	report, err := d.Verify(deep)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range report.Problems {
+		fmt.Println(problem.String())
+	}
+	fmt.Printf("checked %d files, %d broken\n", report.Checked, len(report.Problems))
+
+	if report.OK() {
+		return nil
+	}
+
+	if !repair {
+		return fmt.Errorf("installation is broken, run with -repair to fix it")
+	}
+
+	return d.Repair(report)
+}
+
+// RunGC lists files left over from old versions and deletes them after asking.
+// dryRun only lists them
+func RunGC(dryRun bool) error {
+	cfg, d, err := cliSetup()
+	if err != nil {
+		return err
+	}
+
+	report, err := diskUsage(d, cfg.GameDir)
+	if err != nil {
+		return err
+	}
+
+	for _, orphan := range report.Orphans() {
+		fmt.Println(orphan)
+	}
+
+	for _, c := range report.Categories {
+		fmt.Printf("%s: %s used, %s reclaimable\n", c.Category, formatBytes(float64(c.Total)), formatBytes(float64(c.Reclaimable)))
+	}
+
+	if dryRun || len(report.Orphans()) == 0 {
+		return nil
+	}
+
+	fmt.Printf("delete %d files (%s)? [y/N] ", len(report.Orphans()), formatBytes(float64(report.Reclaimable())))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return nil
+	}
+
+	freed, err := d.CollectGarbage(report)
+	fmt.Printf("freed %s\n", formatBytes(float64(freed)))
+
+	return err
+}
+
+// RunImport installs modpack into a new instance that the launcher opens next time
+func RunImport(packPath string) error {
+	initDirs()
+
+	gameDir, err := utils.ActiveGameDir()
+	if err != nil {
+		return fmt.Errorf("failed to determine game folder: %s", err.Error())
+	}
+
+	cfg, err := readConfigOrDefault(gameDir, "")
+	if err != nil {
+		return err
+	}
+	env := applyEnv(cfg)
+
+	downloader.SetBandwidthLimit(cfg.DownloadLimit * 1024)
+	instance, manual, err := importPack(packPath, cfg, env, slog.New(slog.NewTextHandler(os.Stderr, nil)), nil)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.SetActiveInstance(instance.GameDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("imported into %s\n", instance.GameDir)
+	for _, m := range manual {
+		fmt.Printf("download manually: %s -> %s\n", m.String(), m.Dir)
+	}
+
+	return nil
+}
+
+// RunToggleMod turns mod in mods dir of the last used instance on or off, e.g. sodium.jar.
+// Resource updates keep disabled mods off, same as toggling them in the mods dialog
+func RunToggleMod(fileName string, enabled bool) error {
+	cfg, d, err := cliSetup()
+	if err != nil {
+		return err
+	}
+
+	if err := mods.NewToggler(cfg).WithDownloader(d).SetEnabled(fileName, enabled); err != nil {
+		return err
+	}
+
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	fmt.Printf("%s %s\n", fileName, state)
+
+	return nil
+}
+
+// RunListVersions prints minecraft releases newest first
+// and versions of the configured loader available for the instance's minecraft version
+func RunListVersions() error {
+	cfg, d, err := cliSetup()
+	if err != nil {
+		return err
+	}
+
+	versions, err := d.GetVersions(types.VersionRelease)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range versions {
+		line := v.ID
+		if v.Fabric {
+			line += " fabric"
+		}
+		if v.ID == cfg.Versions.Minecraft {
+			line += " (current)"
+		}
+		fmt.Println(line)
+	}
+
+	loader, err := downloader.LoaderFor(cfg)
+	if err != nil || loader == nil || loader.Name() == downloader.LoaderNeoForge {
+		return err
+	}
+
+	loaderVersions, err := d.GetLoaderVersions(loader.Name(), cfg.Versions.Minecraft)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s versions for minecraft %s:\n", loader.Name(), cfg.Versions.Minecraft)
+	for _, v := range loaderVersions {
+		line := v.Version
+		if !v.Stable {
+			line += " unstable"
+		}
+		if v.Version == loader.Version() {
+			line += " (current)"
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// RunSetVersion switches the last used instance to minecraft mcVersion, "recommended" goes back to
+// the versions launcher resources are made for. Empty loaderVersion picks the newest stable one.
+// Game files are downloaded on the next start
+func RunSetVersion(mcVersion, loaderVersion string) error {
+	_, d, err := cliSetup()
+	if err != nil {
+		return err
+	}
+
+	// persisted without env overrides cliSetup applied
+	gameDir, err := utils.ActiveGameDir()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := utils.ReadPersistedConfig(gameDir)
+	if err != nil {
+		return err
+	}
+
+	if mcVersion == recommendedVersion {
+		if !utils.IsMainGameDir(cfg.GameDir) {
+			return fmt.Errorf("imported instances have no recommended versions")
+		}
+
+		cfg.Versions = recommendedVersions(cfg.Versions)
+		// launcher resources were skipped while versions were picked by player
+		cfg.Versions.Launcher = ""
+	} else if err := setGameVersion(d, cfg, mcVersion, loaderVersion); err != nil {
+		return err
+	}
+
+	if err := utils.PersistConfig(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("switched to minecraft %s\n", cfg.Versions.Minecraft)
+	return nil
+}
+
+// cliDownloader is set up from persisted config of the instance player used last
+func cliDownloader() (*downloader.Downloader, error) {
+	_, d, err := cliSetup()
+	return d, err
+}
+
+func cliSetup() (*config.Config, *downloader.Downloader, error) {
+	initDirs()
+
+	gameDir, err := utils.ActiveGameDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to determine game folder: %s", err.Error())
+	}
+
+	cfg, err := utils.ReadPersistedConfig(gameDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config, is the game installed? %s", err)
+	}
+	applyEnv(cfg)
+
+	// downloads dont need a username
+	var errs config.ValidationErrors
+	for _, err := range cfg.Validate() {
+		if err.Field != "username" {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return nil, nil, fmt.Errorf("invalid config: %v", errs)
+	}
+
+	downloader.SetBandwidthLimit(cfg.DownloadLimit * 1024)
+	return cfg, downloader.New(cfg).WithLogger(slog.New(slog.NewTextHandler(os.Stderr, nil))), nil
+}
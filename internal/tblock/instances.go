@@ -0,0 +1,246 @@
+package tblock
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"github.com/havrydotdev/tblock-launcher/internal/utils"
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/launcher"
+	"github.com/havrydotdev/tblock-launcher/pkg/modpack"
+)
+
+// importModpack installs a modpack into a new instance and switches to it
+func (l *Launcher) importModpack() {
+	open := dialog.NewFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			l.showError(err)
+			return
+		}
+		// importers open the file themselves
+		r.Close()
+
+		l.runImport(r.URI().Path())
+	}, l.w)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".mrpack", ".zip"}))
+	open.Show()
+}
+
+// importInstanceFolder copies a multimc or prism instance folder into a new instance
+func (l *Launcher) importInstanceFolder() {
+	dialog.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+		if err != nil || dir == nil {
+			l.showError(err)
+			return
+		}
+
+		l.runImport(dir.Path())
+	}, l.w)
+}
+
+func (l *Launcher) runImport(packPath string) {
+	name := packName(packPath)
+
+	l.statusText.Set(lang.L("Importing modpack..."))
+	l.progress.SetValue(0)
+	l.progress.Show()
+	go func() {
+		cfg, manual, err := importPack(packPath, l.cfg, l.env, l.log, l.onProgress)
+
+		fyne.Do(func() {
+			l.progress.Hide()
+			l.statusText.Set("")
+			if err != nil {
+				l.showError(fmt.Errorf("failed to import modpack: %v", err))
+				return
+			}
+
+			if err := l.switchInstance(cfg); err != nil {
+				l.showError(err)
+				return
+			}
+
+			if len(manual) == 0 {
+				dialog.ShowInformation(lang.L("Import modpack"), fmt.Sprintf(lang.L("Modpack %s was imported"), name), l.w)
+				return
+			}
+
+			l.showManualDownloads(manual)
+		})
+	}()
+}
+
+// showManualDownloads lists files curseforge wouldnt give us, player downloads them in the browser
+func (l *Launcher) showManualDownloads(manual []modpack.ManualDownload) {
+	lines := make([]string, 0, len(manual))
+	for _, m := range manual {
+		lines = append(lines, fmt.Sprintf("%s\n→ %s", m.String(), m.Dir))
+	}
+
+	text := widget.NewLabel(strings.Join(lines, "\n\n"))
+	text.Wrapping = fyne.TextWrapWord
+	text.Selectable = true
+
+	d := dialog.NewCustom(lang.L("Download these files manually"), lang.L("Close"), container.NewVScroll(text), l.w)
+	d.Resize(fyne.NewSize(500, 350))
+	d.Show()
+}
+
+// packName is file name of the modpack without extension, instance folders keep theirs
+func packName(packPath string) string {
+	name := filepath.Base(packPath)
+	if info, err := os.Stat(packPath); err == nil && !info.IsDir() {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	return name
+}
+
+// importPack installs packPath into a new instance and persists its config.
+// Player settings are copied from cfg without env overrides, onProgress may be nil
+func importPack(packPath string, cfg *config.Config, env config.Overrides, log *slog.Logger, onProgress downloader.ProgressFunc) (*config.Config, []modpack.ManualDownload, error) {
+	format, err := modpack.DetectFormat(packPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gameDir, err := utils.NewInstanceDir(packName(packPath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		instance *config.Config
+		manual   []modpack.ManualDownload
+	)
+	base := env.Restore(cfg)
+	switch format {
+	case modpack.FormatMrpack:
+		instance, err = modpack.ImportMrpack(packPath, gameDir, base, log, onProgress)
+	case modpack.FormatCurseForge:
+		// key may come from env, so it is read from cfg
+		if cfg.CurseForgeAPIKey == "" {
+			return nil, nil, fmt.Errorf("curseforge api key is not set, add it in settings or %s", config.EnvName("curseforge_api_key"))
+		}
+
+		client := downloader.New(cfg).HTTPClient()
+		instance, manual, err = modpack.NewCurseForge(cfg.CurseForgeAPIKey).WithHTTPClient(client).WithLogger(log).WithProgress(onProgress).Import(packPath, gameDir, base)
+	case modpack.FormatMultiMC:
+		instance, err = modpack.ImportMultiMC(packPath, gameDir, base, log)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := utils.PersistConfig(instance); err != nil {
+		return nil, nil, fmt.Errorf("failed to save instance config: %v", err)
+	}
+
+	downloader.New(instance).WithLogger(log).TrimSharedCache()
+
+	return instance, manual, nil
+}
+
+// switchInstance saves config of the current instance and makes launcher use cfg
+func (l *Launcher) switchInstance(cfg *config.Config) error {
+	if l.state == Downloading || l.state == StartedClient {
+		return fmt.Errorf("%s", lang.L("Wait until the game is closed or downloaded"))
+	}
+
+	if err := l.PersistConfig(); err != nil {
+		return err
+	}
+
+	env := applyEnv(cfg)
+	core, err := launcher.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := utils.SetActiveInstance(cfg.GameDir); err != nil {
+		l.log.Warn("failed to remember instance", slog.String("error", err.Error()))
+	}
+
+	l.cfg, l.env, l.core = cfg, env, core
+	switch {
+	case resourcesOutdated(cfg, l.version):
+		l.setState(CanUpdateResources)
+	case core.IsInstalled():
+		l.setState(Ready)
+	default:
+		l.setState(ClientNotInstalled)
+	}
+
+	l.usernameInput.SetText(cfg.Username)
+	l.settings.Hide()
+	l.settings = l.buildSettingsDialog()
+
+	return nil
+}
+
+// lets player go back to the main instance or another imported one
+func (l *Launcher) buildInstanceSelect() *widget.Select {
+	mainInstance := lang.L("Main")
+	dirs := map[string]string{}
+	options := []string{mainInstance}
+
+	instances, err := utils.ListInstances()
+	if err != nil {
+		l.log.Warn("failed to list instances", slog.String("error", err.Error()))
+	}
+	for _, dir := range instances {
+		dirs[filepath.Base(dir)] = dir
+		options = append(options, filepath.Base(dir))
+	}
+
+	current := mainInstance
+	if !utils.IsMainGameDir(l.cfg.GameDir) {
+		current = filepath.Base(l.cfg.GameDir)
+	}
+
+	sel := widget.NewSelect(options, nil)
+	sel.SetSelected(current)
+	sel.OnChanged = func(selected string) {
+		if selected == current {
+			return
+		}
+
+		var (
+			cfg *config.Config
+			err error
+		)
+		if selected == mainInstance {
+			gameDir, dirErr := utils.GetTblockFolderPath()
+			if dirErr != nil {
+				l.showError(dirErr)
+				return
+			}
+			cfg, err = readConfigOrDefault(gameDir, l.version)
+		} else {
+			cfg, err = utils.ReadPersistedConfig(dirs[selected])
+		}
+
+		if err != nil {
+			err = fmt.Errorf("failed to read instance config: %v", err)
+		} else {
+			err = l.switchInstance(cfg)
+		}
+
+		if err != nil {
+			l.showError(err)
+			sel.SetSelected(current)
+		}
+	}
+
+	return sel
+}
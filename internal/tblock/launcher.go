@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -22,6 +26,9 @@ import (
 	"github.com/havrydotdev/tblock-launcher/pkg/config"
 	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
 	"github.com/havrydotdev/tblock-launcher/pkg/launcher"
+	"github.com/havrydotdev/tblock-launcher/pkg/modpack"
+	"github.com/havrydotdev/tblock-launcher/pkg/modrinth"
+	"github.com/havrydotdev/tblock-launcher/pkg/mods"
 	"github.com/mouuff/go-rocket-update/pkg/provider"
 	"github.com/mouuff/go-rocket-update/pkg/updater"
 )
@@ -77,19 +84,21 @@ var (
 // TODO: refactor to not be so cluttered
 type Launcher struct {
 	cfg     *config.Config
+	env     config.Overrides
 	version string
-	core    *launcher.FabricLauncher
+	core    launcher.Launcher
 	state   LauncherState
 	isDev   bool
 	log     *slog.Logger
 
-	w          fyne.Window
-	a          fyne.App
-	u          *updater.Updater
-	mainButton *widget.Button
-	progress   *widget.ProgressBar
-	settings   *dialog.CustomDialog
-	statusText binding.String
+	w             fyne.Window
+	a             fyne.App
+	u             *updater.Updater
+	mainButton    *widget.Button
+	usernameInput *widget.Entry
+	progress      *widget.ProgressBar
+	settings      *dialog.CustomDialog
+	statusText    binding.String
 }
 
 func NewLauncher() (*Launcher, error) {
@@ -101,12 +110,19 @@ func NewLauncher() (*Launcher, error) {
 	a := app.NewWithID("com.github.tblockmc.launcher")
 	a.Settings().SetTheme(newTheme())
 
+	initDirs()
 	cfg, err := ReadPersistedConfigOrDefault(a)
 	if err != nil {
 		return nil, err
 	}
+	env := applyEnv(cfg)
 
-	core := launcher.NewFabricLauncher(cfg)
+	core, err := launcher.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	downloader.SetBandwidthLimit(cfg.DownloadLimit * 1024)
 
 	uk, err := static.Translations.ReadFile("translations/uk.json")
 	if err != nil {
@@ -134,7 +150,7 @@ func NewLauncher() (*Launcher, error) {
 	w := a.NewWindow(fmt.Sprintf("%s %s", lang.L("TBlockMC"), version))
 
 	state := Ready
-	if !core.IsFabricInstalled() {
+	if !core.IsInstalled() {
 		state = ClientNotInstalled
 	}
 
@@ -148,7 +164,7 @@ func NewLauncher() (*Launcher, error) {
 		state = CanUpdate
 	}
 
-	if utils.McVersion != cfg.Versions.Minecraft || utils.FabricLoaderVersion != cfg.Versions.FabricLoader || version != cfg.Versions.Launcher {
+	if resourcesOutdated(cfg, version) {
 		state = CanUpdateResources
 	}
 
@@ -165,11 +181,16 @@ func NewLauncher() (*Launcher, error) {
 	logger := buildLogger(logWriter)
 	slog.SetDefault(logger)
 
+	// player sees them once the window is built, see showConfigErrors
+	for _, err := range cfg.Validate() {
+		logger.Warn("invalid config value", slog.String("field", err.Field), slog.String("error", err.Message))
+	}
+
 	return &Launcher{
 		version:    version,
 		statusText: statusText,
 		state:      state, w: w, u: u, a: a,
-		core: core, cfg: cfg, isDev: isDev,
+		core: core, cfg: cfg, env: env, isDev: isDev,
 		log: logger, progress: progress,
 	}, nil
 }
@@ -181,6 +202,7 @@ func (l *Launcher) Run() {
 	}
 
 	l.buildUI()
+	l.showConfigErrors()
 
 	l.w.Resize(fyne.NewSize(640, 480))
 	l.w.SetFixedSize(true)
@@ -193,26 +215,111 @@ func (l *Launcher) openSettings() {
 }
 
 func (l *Launcher) buildSettingsDialog() *dialog.CustomDialog {
+	v := l.newSettingsValidator()
+
 	javaPathInputLabel := widget.NewLabel(lang.L("Java path"))
 	javaPathInput := widget.NewEntry()
 	javaPathInput.SetText(l.cfg.JavaPath)
 	javaPathInput.OnChanged = func(javaPath string) {
 		l.cfg.JavaPath = javaPath
+		v.validate()
 	}
+	v.add("java_path", javaPathInput)
 
 	memoryInputLabel := widget.NewLabel(lang.L("Minecraft memory"))
 	memoryInput := widget.NewEntry()
 	memoryInput.SetText(l.cfg.Memory)
 	memoryInput.OnChanged = func(memory string) {
 		l.cfg.Memory = memory
+		v.validate()
 	}
+	v.add("memory", memoryInput)
 
 	jvmArgsLabel := widget.NewLabel(lang.L("JVM arguments"))
 	jvmArgsInput := widget.NewEntry()
 	jvmArgsInput.SetText(l.cfg.JvmArgs)
 	jvmArgsInput.OnChanged = func(jvmArgs string) {
 		l.cfg.JvmArgs = jvmArgs
+		v.validate()
+	}
+	v.add("jvm_args", jvmArgsInput)
+
+	downloadLimitLabel := widget.NewLabel(lang.L("Download limit, KB/s"))
+	downloadLimitInput := widget.NewEntry()
+	downloadLimitInput.SetPlaceHolder(lang.L("Unlimited"))
+	downloadLimitInput.Validator = numberValidator
+	if l.cfg.DownloadLimit > 0 {
+		downloadLimitInput.SetText(strconv.FormatInt(l.cfg.DownloadLimit, 10))
 	}
+	downloadLimitInput.OnChanged = func(limit string) {
+		kbps, err := strconv.ParseInt(strings.TrimSpace(limit), 10, 64)
+		if err != nil || kbps < 0 {
+			kbps = 0
+		}
+
+		// applies to downloads already running too
+		l.cfg.DownloadLimit = kbps
+		downloader.SetBandwidthLimit(kbps * 1024)
+		v.validate()
+	}
+	v.add("download_limit", downloadLimitInput)
+
+	sharedCacheLabel := widget.NewLabel(lang.L("Shared cache folder"))
+	sharedCacheInput := widget.NewEntry()
+	sharedCacheInput.SetPlaceHolder(lang.L("Disabled"))
+	sharedCacheInput.SetText(l.cfg.SharedCacheDir)
+	sharedCacheInput.OnChanged = func(dir string) {
+		l.cfg.SharedCacheDir = strings.TrimSpace(dir)
+		v.validate()
+	}
+	v.add("shared_cache_dir", sharedCacheInput)
+
+	sharedCacheSizeLabel := widget.NewLabel(lang.L("Shared cache limit, MB"))
+	sharedCacheSizeInput := widget.NewEntry()
+	sharedCacheSizeInput.SetPlaceHolder(lang.L("Unlimited"))
+	sharedCacheSizeInput.Validator = numberValidator
+	if l.cfg.SharedCacheSize > 0 {
+		sharedCacheSizeInput.SetText(strconv.FormatInt(l.cfg.SharedCacheSize, 10))
+	}
+	sharedCacheSizeInput.OnChanged = func(size string) {
+		mb, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err != nil || mb < 0 {
+			mb = 0
+		}
+		l.cfg.SharedCacheSize = mb
+		v.validate()
+	}
+	v.add("shared_cache_size", sharedCacheSizeInput)
+
+	curseForgeKeyLabel := widget.NewLabel(lang.L("CurseForge API key"))
+	curseForgeKeyInput := widget.NewPasswordEntry()
+	curseForgeKeyInput.SetText(l.cfg.CurseForgeAPIKey)
+	curseForgeKeyInput.OnChanged = func(key string) {
+		l.cfg.CurseForgeAPIKey = strings.TrimSpace(key)
+	}
+	v.validate()
+
+	versionLabel := widget.NewLabel(lang.L("Minecraft version"))
+	loaderVersionLabel := widget.NewLabel(lang.L("Loader version"))
+	versionSelect, loaderVersionSelect, refreshLoaderVersions := l.buildVersionSelects()
+
+	loaderLabel := widget.NewLabel(lang.L("Game mode"))
+	loaderSelect := l.buildLoaderSelect(refreshLoaderVersions)
+
+	instanceLabel := widget.NewLabel(lang.L("Instance"))
+	instanceSelect := l.buildInstanceSelect()
+
+	modUpdatesButton := widget.NewButton(lang.L("Check mod updates"), l.checkModUpdates)
+	rollbackButton := widget.NewButton(lang.L("Undo mod updates"), l.rollbackModUpdates)
+	importButton := widget.NewButton(lang.L("Import modpack"), l.importModpack)
+	importFolderButton := widget.NewButton(lang.L("Import instance folder"), l.importInstanceFolder)
+	exportButton := widget.NewButton(lang.L("Export modpack"), l.exportModpack)
+	proxyButton := widget.NewButton(lang.L("Proxy"), l.openProxySettings)
+	cleanupButton := widget.NewButton(lang.L("Free up disk space"), l.freeDiskSpace)
+	deepVerify := widget.NewCheck(lang.L("Deep verify"), nil)
+	repairButton := widget.NewButton(lang.L("Repair installation"), func() {
+		l.repairInstallation(deepVerify.Checked)
+	})
 
 	return dialog.NewCustom(lang.L("Settings"), lang.L("Close"),
 		container.NewVBox(
@@ -221,21 +328,204 @@ func (l *Launcher) buildSettingsDialog() *dialog.CustomDialog {
 				javaPathInputLabel, javaPathInput,
 				memoryInputLabel, memoryInput,
 				jvmArgsLabel, jvmArgsInput,
+				downloadLimitLabel, downloadLimitInput,
+				sharedCacheLabel, sharedCacheInput,
+				sharedCacheSizeLabel, sharedCacheSizeInput,
+				curseForgeKeyLabel, curseForgeKeyInput,
+				loaderLabel, loaderSelect,
+				versionLabel, versionSelect,
+				loaderVersionLabel, loaderVersionSelect,
+				instanceLabel, instanceSelect,
 			),
+			v.summary,
+			container.NewGridWithColumns(2, modUpdatesButton, rollbackButton),
+			container.NewGridWithColumns(3, importButton, importFolderButton, exportButton),
+			proxyButton,
+			cleanupButton,
+			container.NewBorder(nil, nil, nil, deepVerify, repairButton),
 			layout.NewSpacer(),
 		), l.w,
 	)
 }
 
+// lets player pick the mod loader or run plain vanilla, e.g. to check whether mods cause a crash.
+// onChanged runs after the loader was switched
+func (l *Launcher) buildLoaderSelect(onChanged func()) *widget.Select {
+	labels := map[string]string{
+		downloader.LoaderFabric:  lang.L("With mods"),
+		downloader.LoaderQuilt:   lang.L("With mods (Quilt)"),
+		downloader.LoaderVanilla: lang.L("Vanilla"),
+	}
+	loaders := []string{downloader.LoaderFabric, downloader.LoaderQuilt, downloader.LoaderVanilla}
+
+	// imported neoforge modpacks can switch back to it
+	if l.cfg.Versions.NeoForge != "" {
+		labels[downloader.LoaderNeoForge] = lang.L("With mods (NeoForge)")
+		loaders = append(loaders, downloader.LoaderNeoForge)
+	}
+
+	options := make([]string, 0, len(loaders))
+	for _, loader := range loaders {
+		options = append(options, labels[loader])
+	}
+
+	current := l.cfg.Versions.Loader
+	if current == "" {
+		current = downloader.LoaderFabric
+	}
+
+	sel := widget.NewSelect(options, func(selected string) {
+		loader := loaders[slices.Index(options, selected)]
+		if loader == current {
+			return
+		}
+
+		l.cfg.Versions.Loader = loader
+		if loader == downloader.LoaderQuilt && l.cfg.Versions.QuiltLoader == "" {
+			l.cfg.Versions.QuiltLoader = utils.QuiltLoaderVersion
+		}
+		current = loader
+
+		l.reloadCore()
+		onChanged()
+	})
+	sel.SetSelected(labels[current])
+
+	return sel
+}
+
+// reloadCore rebuilds game launcher after loader or versions changed
+func (l *Launcher) reloadCore() {
+	core, err := launcher.New(l.cfg)
+	if err != nil {
+		l.showError(err)
+		return
+	}
+	l.core = core
+
+	// busy states finish on their own
+	if l.state != Ready && l.state != ClientNotInstalled && l.state != CanUpdateResources {
+		return
+	}
+
+	switch {
+	case resourcesOutdated(l.cfg, l.version):
+		l.setState(CanUpdateResources)
+	case core.IsInstalled():
+		l.setState(Ready)
+	default:
+		l.setState(ClientNotInstalled)
+	}
+}
+
+func (l *Launcher) modUpdater() *mods.Updater {
+	d := downloader.New(l.cfg).WithLogger(l.log)
+	u := mods.NewUpdater(l.cfg, modrinth.New().WithHTTPClient(d.HTTPClient()).WithLogger(l.log)).WithDownloader(d).WithLogger(l.log)
+	if loader, err := downloader.LoaderFor(l.cfg); err == nil && loader != nil {
+		u = u.WithLoader(loader.Name())
+	}
+
+	return u
+}
+
+func (l *Launcher) checkModUpdates() {
+	u := l.modUpdater()
+
+	go func() {
+		updates, err := u.CheckUpdates()
+		if err != nil {
+			fyne.Do(func() { l.showError(err) })
+			return
+		}
+
+		fyne.Do(func() {
+			if len(updates) == 0 {
+				dialog.ShowInformation(lang.L("Mod updates"), lang.L("All mods are up to date"), l.w)
+				return
+			}
+
+			lines := make([]string, 0, len(updates))
+			for _, update := range updates {
+				lines = append(lines, fmt.Sprintf("%s\n%s", update.String(), update.ChangelogURL()))
+			}
+
+			confirm := dialog.NewConfirm(lang.L("Mod updates"), strings.Join(lines, "\n\n"), func(ok bool) {
+				if !ok {
+					return
+				}
+
+				go func() {
+					err := u.Apply(updates)
+					fyne.Do(func() {
+						if err != nil {
+							l.showError(err)
+							return
+						}
+
+						dialog.ShowInformation(lang.L("Mod updates"), fmt.Sprintf(lang.L("Updated %d mods"), len(updates)), l.w)
+					})
+				}()
+			}, l.w)
+			confirm.SetConfirmText(lang.L("Update"))
+			confirm.Show()
+		})
+	}()
+}
+
+// rollbackModUpdates restores jars replaced by the last mod update
+func (l *Launcher) rollbackModUpdates() {
+	u := l.modUpdater()
+	if !u.CanRollback() {
+		dialog.ShowInformation(lang.L("Mod updates"), lang.L("Nothing to undo"), l.w)
+		return
+	}
+
+	dialog.ShowConfirm(lang.L("Mod updates"), lang.L("Restore mods replaced by the last update?"), func(ok bool) {
+		if !ok {
+			return
+		}
+
+		go func() {
+			err := u.Rollback()
+			fyne.Do(func() {
+				if err != nil {
+					l.showError(err)
+					return
+				}
+
+				dialog.ShowInformation(lang.L("Mod updates"), lang.L("Mod updates were undone"), l.w)
+			})
+		}()
+	}, l.w)
+}
+
+func (l *Launcher) exportModpack() {
+	save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil || w == nil {
+			l.showError(err)
+			return
+		}
+		// ExportMrpack creates the file itself
+		w.Close()
+
+		go func() {
+			err := modpack.ExportMrpack(l.cfg, w.URI().Path(), lang.L("TBlockMC"), l.version)
+			fyne.Do(func() { l.showError(err) })
+		}()
+	}, l.w)
+	save.SetFileName("tblockmc.mrpack")
+	save.Show()
+}
+
 func (l *Launcher) buildUI() {
 	l.mainButton = l.buildMainButton()
 	l.settings = l.buildSettingsDialog()
 
-	usernameInput := l.buildUsernameInput()
+	l.usernameInput = l.buildUsernameInput()
 
 	bottom := container.New(
 		NewRatioLayout(2.0),
-		usernameInput, l.mainButton,
+		l.usernameInput, l.mainButton,
 	)
 
 	background := canvas.NewImageFromReader(
@@ -244,7 +534,10 @@ func (l *Launcher) buildUI() {
 
 	topMenu := container.NewBorder(
 		nil, nil, nil,
-		widget.NewButtonWithIcon("", theme.Icon(theme.IconNameSettings), l.openSettings),
+		container.NewHBox(
+			widget.NewButtonWithIcon("", theme.Icon(theme.IconNameList), l.openMods),
+			widget.NewButtonWithIcon("", theme.Icon(theme.IconNameSettings), l.openSettings),
+		),
 	)
 
 	progress := container.NewVBox(
@@ -276,6 +569,7 @@ func (l *Launcher) buildUsernameInput() *widget.Entry {
 	}
 	entry.Text = l.cfg.Username
 	entry.PlaceHolder = lang.L("Enter your username")
+	entry.Validator = l.usernameValidator
 
 	return entry
 }
@@ -290,6 +584,23 @@ func (l *Launcher) showError(err error) {
 	d.Show()
 }
 
+func (l *Launcher) showConflicts(conflicts []downloader.ResourceConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		lines = append(lines, c.String())
+	}
+
+	fyne.Do(func() {
+		d := dialog.NewInformation(lang.L("Some files were kept"), strings.Join(lines, "\n"), l.w)
+		d.Resize(fyne.NewSize(450, 200))
+		d.Show()
+	})
+}
+
 func (l *Launcher) buildMainButton() *widget.Button {
 	return widget.NewButton(lang.L(mainBtnTexts[l.state]), func() {
 		switch l.state {
@@ -329,89 +640,211 @@ func (l *Launcher) buildMainButton() *widget.Button {
 				fyne.Do(func() { l.setState(Ready) })
 			}()
 		case Ready:
+			if err := l.usernameValidator(l.cfg.Username); err != nil {
+				l.showError(fmt.Errorf("%s: %v", lang.L("Username"), err))
+				return
+			}
+
 			l.setState(StartedClient)
 			l.statusText.Set("")
 
-			err := discord.SetPlayingActivity()
-			if err != nil {
-				l.log.Warn("failed to set playing activity", slog.String("error", err.Error()))
+			go l.checkAndLaunch()
+		}
+	})
+}
+
+// checkAndLaunch validates mods first and lets player decide whether
+// to launch anyway if something is off
+func (l *Launcher) checkAndLaunch() {
+	problems, err := l.checkMods()
+	if err != nil {
+		l.log.Warn("failed to check mods", slog.String("error", err.Error()))
+	}
+
+	if err == nil && len(problems) == 0 {
+		l.launch()
+		return
+	}
+
+	lines := make([]string, 0, len(problems)+1)
+	if err != nil {
+		lines = append(lines, fmt.Sprintf(lang.L("Failed to check mods: %s"), err))
+	}
+	for _, p := range problems {
+		lines = append(lines, "• "+p.String())
+	}
+
+	fyne.Do(func() {
+		confirm := dialog.NewConfirm(lang.L("Mod problems found"), strings.Join(lines, "\n"), func(ok bool) {
+			if !ok {
+				l.setState(Ready)
+				return
 			}
 
-			go func() {
-				if err := l.core.Launch(); err != nil {
-					l.showError(err)
-				}
+			go l.launch()
+		}, l.w)
+		confirm.SetConfirmText(lang.L("Launch anyway"))
+		confirm.SetDismissText(lang.L("Cancel"))
+		confirm.Show()
+	})
+}
 
-				fyne.Do(func() {
-					l.setState(Ready)
-					l.w.Show()
-				})
-			}()
-		}
+func (l *Launcher) checkMods() ([]mods.Problem, error) {
+	loader, err := downloader.LoaderFor(l.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// mods folder is ignored without a loader, neoforge mods dont have fabric.mod.json
+	if loader == nil || loader.Name() == downloader.LoaderNeoForge {
+		return nil, nil
+	}
+
+	list, err := mods.NewInspector(l.cfg).WithLogger(l.log).List()
+	if err != nil {
+		return nil, err
+	}
+
+	return mods.Validate(list, mods.Environment{
+		Minecraft:     l.cfg.Versions.Minecraft,
+		Loader:        loader.Name(),
+		LoaderVersion: loader.Version(),
+		Java:          downloader.JavaVersion,
+	}), nil
+}
+
+func (l *Launcher) launch() {
+	err := discord.SetPlayingActivity()
+	if err != nil {
+		l.log.Warn("failed to set playing activity", slog.String("error", err.Error()))
+	}
+
+	if err := l.core.Launch(); err != nil {
+		l.showError(err)
+	}
+
+	fyne.Do(func() {
+		l.setState(Ready)
+		l.w.Show()
 	})
 }
 
 func (l *Launcher) updateResources() error {
-	d := downloader.New(l.cfg).WithLogger(l.log)
-	if utils.McVersion != l.cfg.Versions.Minecraft || utils.FabricLoaderVersion != l.cfg.Versions.FabricLoader {
+	d := downloader.New(l.cfg).WithLogger(l.log).WithProgress(l.onProgress)
+
+	l.progress.Show()
+	defer l.progress.Hide()
+
+	if versionsOutdated(l.cfg) {
 		err := d.DeleteVersion()
 		if err != nil {
 			return err
 		}
 
+		l.cfg.Versions = recommendedVersions(l.cfg.Versions)
 		if err := l.installVersion(d); err != nil {
 			return err
 		}
-	}
 
-	if l.a.Metadata().Version != l.cfg.Versions.Launcher {
-		err := d.DeleteModsAndResourcepacks()
+		core, err := launcher.New(l.cfg)
 		if err != nil {
 			return err
 		}
+		l.core = core
+	}
 
+	if l.a.Metadata().Version != l.cfg.Versions.Launcher {
 		if err := l.downloadMods(d); err != nil {
 			return err
 		}
 	}
 
 	l.cfg.Versions.Launcher = l.a.Metadata().Version
+	d.TrimSharedCache()
+	l.finishStatus(d)
 
 	return nil
 }
 
 func (l *Launcher) install() error {
-	d := downloader.New(l.cfg).WithLogger(l.log)
+	d := downloader.New(l.cfg).WithLogger(l.log).WithProgress(l.onProgress)
 
 	l.progress.Show()
 
-	if err := l.installVersion(d); err != nil {
-		return err
-	}
+	// java downloads alongside game files, scheduler keeps it
+	// from slowing down smaller ones
+	// its progress is shown only once game files are done, so status doesnt jump between the two
+	var showJava atomic.Bool
+	javaD := downloader.New(l.cfg).WithLogger(l.log).WithProgress(func(e downloader.ProgressEvent) {
+		if showJava.Load() {
+			l.onProgress(e)
+		}
+	})
 
-	if err := l.downloadMods(d); err != nil {
-		return err
-	}
+	javaDone := make(chan error, 1)
+	go func() { javaDone <- javaD.DownloadJava() }()
 
-	l.statusText.Set(lang.L("Writing static files..."))
-	if err := d.WriteOverrides(overrides); err != nil {
-		return fmt.Errorf("failed to write static files: %s", err)
+	if err := l.installGame(d); err != nil {
+		return err
 	}
 
+	// some loaders run java while installing
+	showJava.Store(true)
 	l.statusText.Set(lang.L("Downloading Java..."))
-	if err := d.DownloadJava(); err != nil {
+	if err := <-javaDone; err != nil {
 		return fmt.Errorf("failed to download java: %s", err)
 	}
 
 	l.cfg.JavaPath = d.GetJavaPath()
-	l.statusText.Set("")
+
+	if err := l.installLoader(d); err != nil {
+		return err
+	}
+
+	// imported instances keep mods & settings of their modpack
+	if usesLauncherResources(l.cfg) {
+		if err := l.downloadMods(d); err != nil {
+			return err
+		}
+
+		l.statusText.Set(lang.L("Writing static files..."))
+		if err := d.WriteOverrides(overrides); err != nil {
+			return fmt.Errorf("failed to write static files: %s", err)
+		}
+	}
+	d.TrimSharedCache()
+	l.finishStatus(d)
 	l.progress.Hide()
 	return nil
 }
 
+// resourcesOutdated reports whether the main instance was set up by an older launcher.
+// Imported instances and versions player picked never get launcher resources
+func resourcesOutdated(cfg *config.Config, version string) bool {
+	if !usesLauncherResources(cfg) {
+		return false
+	}
+
+	return versionsOutdated(cfg) || version != cfg.Versions.Launcher
+}
+
+// finishStatus clears status text, unless some metadata came from cache,
+// then player should know versions may be outdated
+func (l *Launcher) finishStatus(d *downloader.Downloader) {
+	if d.Offline() {
+		l.statusText.Set(lang.L("Offline: using cached version data"))
+		return
+	}
+
+	l.statusText.Set("")
+}
+
 func (l *Launcher) downloadMods(d *downloader.Downloader) error {
 	l.statusText.Set(lang.L("Downloading mods..."))
-	if err := d.DownloadResouces(resources); err != nil {
+	conflicts, err := d.SyncResources(resources)
+	// files kept on disk are worth knowing about even if some downloads failed
+	l.showConflicts(conflicts)
+	if err != nil {
 		return fmt.Errorf("failed to download mods: %s", err)
 	}
 
@@ -419,6 +852,15 @@ func (l *Launcher) downloadMods(d *downloader.Downloader) error {
 }
 
 func (l *Launcher) installVersion(d *downloader.Downloader) error {
+	if err := l.installGame(d); err != nil {
+		return err
+	}
+
+	return l.installLoader(d)
+}
+
+// installGame downloads vanilla client, libraries & assets at once
+func (l *Launcher) installGame(d *downloader.Downloader) error {
 	l.statusText.Set(lang.L("Getting version info..."))
 	versionURL, err := d.GetVersionURL()
 	if err != nil {
@@ -430,35 +872,29 @@ func (l *Launcher) installVersion(d *downloader.Downloader) error {
 		return fmt.Errorf("failed to get version details: %s", err)
 	}
 
-	l.statusText.Set(lang.L("Downloading Minecraft jar..."))
-	if err := d.DownloadClient(details, l.progressCallback); err != nil {
-		return fmt.Errorf("failed to download minecraft jar: %s", err)
-	}
+	l.statusText.Set(lang.L("Downloading Minecraft..."))
+	return d.InstallGame(details)
+}
 
-	l.statusText.Set(lang.L("Downloading libraries..."))
-	if err := d.DownloadLibraries(details.Libraries, l.progressCallback); err != nil {
-		return fmt.Errorf("failed to download minecraft libraries: %s", err)
+func (l *Launcher) installLoader(d *downloader.Downloader) error {
+	loader, err := downloader.LoaderFor(l.cfg)
+	if err != nil {
+		return err
 	}
 
-	l.statusText.Set(lang.L("Downloading assets..."))
-	if err := d.DownloadAssets(details.AssetIndex, l.progressCallback); err != nil {
-		return fmt.Errorf("failed to download minecraft assets: %s", err)
+	// vanilla
+	if loader == nil {
+		return nil
 	}
 
-	l.statusText.Set(lang.L("Downloading fabric..."))
-	if err := d.InstallFabric(); err != nil {
-		return fmt.Errorf("failed to download fabric: %s", err)
+	l.statusText.Set(fmt.Sprintf(lang.L("Downloading %s..."), loader.Name()))
+	if err := d.InstallLoader(loader); err != nil {
+		return fmt.Errorf("failed to download %s: %s", loader.Name(), err)
 	}
 
 	return nil
 }
 
-func (l *Launcher) progressCallback(downloaded, total int64) {
-	fyne.Do(func() {
-		l.progress.SetValue(1.0 / float64(total) * float64(downloaded))
-	})
-}
-
 func (l *Launcher) PersistConfig() error {
-	return utils.PersistConfig(l.cfg)
+	return utils.PersistConfig(l.env.Restore(l.cfg))
 }
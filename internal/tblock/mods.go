@@ -0,0 +1,139 @@
+package tblock
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/modrinth"
+	"github.com/havrydotdev/tblock-launcher/pkg/mods"
+)
+
+func (l *Launcher) openMods() {
+	go func() {
+		list, err := mods.NewInspector(l.cfg).WithLogger(l.log).List()
+		fyne.Do(func() {
+			if err != nil {
+				l.showError(err)
+				return
+			}
+
+			addButton := widget.NewButton(lang.L("Add from Modrinth"), l.addMods)
+			d := dialog.NewCustom(lang.L("Mods"), lang.L("Close"),
+				container.NewBorder(nil, addButton, nil, nil, l.buildModList(list)), l.w)
+			d.Resize(fyne.NewSize(500, 380))
+			d.Show()
+		})
+	}()
+}
+
+func (l *Launcher) buildModList(list []mods.Mod) fyne.CanvasObject {
+	if len(list) == 0 {
+		return widget.NewLabel(lang.L("No mods installed"))
+	}
+
+	toggler := mods.NewToggler(l.cfg).WithDownloader(downloader.New(l.cfg).WithLogger(l.log))
+
+	return widget.NewList(
+		func() int { return len(list) },
+		func() fyne.CanvasObject {
+			icon := canvas.NewImageFromResource(theme.FileIcon())
+			icon.FillMode = canvas.ImageFillContain
+			icon.SetMinSize(fyne.NewSize(32, 32))
+
+			return container.NewBorder(nil, nil, icon, widget.NewCheck("", nil),
+				container.NewVBox(widget.NewLabel(""), widget.NewLabel("")),
+			)
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			mod := list[id]
+			row := item.(*fyne.Container)
+			labels := row.Objects[0].(*fyne.Container)
+			icon := row.Objects[1].(*canvas.Image)
+			enabled := row.Objects[2].(*widget.Check)
+
+			title, subtitle := labels.Objects[0].(*widget.Label), labels.Objects[1].(*widget.Label)
+			if mod.Error != nil {
+				title.SetText(mod.DisplayName())
+				subtitle.Importance = widget.DangerImportance
+				subtitle.SetText(fmt.Sprintf(lang.L("Could not be read: %s"), mod.Error))
+			} else {
+				title.SetText(fmt.Sprintf("%s %s", mod.DisplayName(), mod.Metadata.Version))
+				subtitle.Importance = widget.MediumImportance
+				subtitle.SetText(strings.Join(mod.Metadata.AuthorNames(), ", "))
+			}
+
+			if len(mod.IconData) > 0 {
+				icon.Resource = fyne.NewStaticResource(mod.FileName, mod.IconData)
+			} else {
+				icon.Resource = theme.FileIcon()
+			}
+			icon.Refresh()
+
+			enabled.OnChanged = nil
+			enabled.SetChecked(!mod.Disabled)
+			enabled.OnChanged = func(checked bool) {
+				if err := toggler.SetEnabled(mod.FileName, checked); err != nil {
+					l.showError(err)
+					return
+				}
+
+				list[id].Disabled = !checked
+			}
+		},
+	)
+}
+
+// addMods installs modrinth projects with their dependencies, slugs or ids separated by commas
+func (l *Launcher) addMods() {
+	input := widget.NewEntry()
+	input.SetPlaceHolder("sodium, iris")
+
+	dialog.ShowForm(lang.L("Add from Modrinth"), lang.L("Install"), lang.L("Cancel"),
+		[]*widget.FormItem{widget.NewFormItem(lang.L("Mods"), input)},
+		func(ok bool) {
+			var projects []string
+			for _, p := range strings.Split(input.Text, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					projects = append(projects, p)
+				}
+			}
+
+			if !ok || len(projects) == 0 {
+				return
+			}
+
+			d := downloader.New(l.cfg).WithLogger(l.log)
+			i := mods.NewInstaller(l.cfg, modrinth.New().WithHTTPClient(d.HTTPClient()).WithLogger(l.log)).WithDownloader(d).WithLogger(l.log)
+			if loader, err := downloader.LoaderFor(l.cfg); err == nil && loader != nil {
+				i = i.WithLoader(loader.Name())
+			}
+
+			go func() {
+				installed, err := i.Install(projects)
+				fyne.Do(func() {
+					if err != nil {
+						l.showError(err)
+						return
+					}
+
+					names := make([]string, 0, len(installed))
+					for _, v := range installed {
+						names = append(names, fmt.Sprintf("%s %s", v.Name, v.VersionNumber))
+					}
+					if len(names) == 0 {
+						names = append(names, lang.L("All mods are already installed"))
+					}
+
+					dialog.ShowInformation(lang.L("Add from Modrinth"), strings.Join(names, "\n"), l.w)
+				})
+			}()
+		}, l.w)
+}
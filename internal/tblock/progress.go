@@ -1,6 +1,15 @@
 package tblock
 
-import "fyne.io/fyne/v2"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
 
 type progressBarLayout struct {
 	height float32
@@ -20,3 +29,82 @@ func (p *progressBarLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
 	}
 	return fyne.NewSize(0, p.height)
 }
+
+// status label for each phase, translations are shared with plain status texts
+var phaseTexts = map[downloader.Phase]string{
+	downloader.PhaseClient:    "Downloading Minecraft jar...",
+	downloader.PhaseGame:      "Downloading Minecraft...",
+	downloader.PhaseLibraries: "Downloading libraries...",
+	downloader.PhaseAssets:    "Downloading assets...",
+	downloader.PhaseJava:      "Downloading Java...",
+	downloader.PhaseLoader:    "Downloading mod loader...",
+	downloader.PhaseMods:      "Downloading mods...",
+	downloader.PhaseModpack:   "Importing modpack...",
+	downloader.PhaseVerify:    "Verifying installation...",
+	downloader.PhaseRepair:    "Repairing files...",
+}
+
+// onProgress updates progress bar & status label, called from download goroutines
+func (l *Launcher) onProgress(e downloader.ProgressEvent) {
+	status := formatProgress(e)
+
+	fyne.Do(func() {
+		l.progress.SetValue(e.Fraction())
+		l.statusText.Set(status)
+	})
+}
+
+// e.g. "Downloading assets — 2,341/4,102 — 8.4 MB/s — 0:37 left"
+func formatProgress(e downloader.ProgressEvent) string {
+	parts := []string{strings.TrimSuffix(lang.L(phaseTexts[e.Phase]), "...")}
+
+	if e.FilesTotal > 1 {
+		parts = append(parts, formatCount(e.FilesDone)+"/"+formatCount(e.FilesTotal))
+	}
+
+	if !e.Done && e.Throughput > 0 {
+		parts = append(parts, formatBytes(e.Throughput)+"/s")
+	}
+
+	if !e.Done && e.ETA > 0 {
+		parts = append(parts, fmt.Sprintf(lang.L("%s left"), formatDuration(e.ETA)))
+	}
+
+	return strings.Join(parts, " — ")
+}
+
+// 4102 -> 4,102
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
+
+func formatBytes(b float64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+
+	i := 0
+	for b >= 1024 && i < len(units)-1 {
+		b /= 1024
+		i++
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", b, units[i])
+	}
+
+	return fmt.Sprintf("%.1f %s", b, units[i])
+}
+
+// 0:37, 12:05 or 1:02:03
+func formatDuration(d time.Duration) string {
+	secs := int(d.Round(time.Second).Seconds())
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
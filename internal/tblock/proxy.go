@@ -0,0 +1,112 @@
+package tblock
+
+import (
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+var proxyTypes = []string{config.ProxyHTTP, config.ProxyHTTPS, config.ProxySOCKS5}
+
+// openProxySettings edits proxy used for downloads & by the game,
+// new settings apply to the next download
+func (l *Launcher) openProxySettings() {
+	none := lang.L("No proxy")
+	v := l.newSettingsValidator()
+
+	// game gets credentials differently from the launcher, player should know how
+	note := widget.NewLabel("")
+	note.Wrapping = fyne.TextWrapWord
+	note.Importance = widget.WarningImportance
+	updateNote := func() {
+		switch {
+		case !l.cfg.Proxy.Enabled() || l.cfg.Proxy.Username == "":
+			note.Hide()
+			return
+		case l.cfg.Proxy.Type == config.ProxySOCKS5:
+			note.SetText(lang.L("The game gets the proxy password on its command line, other programs on this computer can see it"))
+		default:
+			note.SetText(lang.L("The game connects to http proxies without credentials, only launcher downloads use them"))
+		}
+		note.Show()
+	}
+
+	typeSelect := widget.NewSelect(append([]string{none}, proxyTypes...), func(selected string) {
+		if selected == none {
+			selected = ""
+		}
+		l.cfg.Proxy.Type = selected
+		v.validate()
+		updateNote()
+	})
+	if l.cfg.Proxy.Type == "" {
+		typeSelect.SetSelected(none)
+	} else {
+		typeSelect.SetSelected(l.cfg.Proxy.Type)
+	}
+
+	hostInput := widget.NewEntry()
+	hostInput.SetText(l.cfg.Proxy.Host)
+	hostInput.OnChanged = func(host string) {
+		l.cfg.Proxy.Host = strings.TrimSpace(host)
+		v.validate()
+		updateNote()
+	}
+	v.add("proxy.host", hostInput)
+
+	portInput := widget.NewEntry()
+	portInput.Validator = numberValidator
+	if l.cfg.Proxy.Port > 0 {
+		portInput.SetText(strconv.Itoa(l.cfg.Proxy.Port))
+	}
+	portInput.OnChanged = func(port string) {
+		l.cfg.Proxy.Port, _ = strconv.Atoi(strings.TrimSpace(port))
+		v.validate()
+	}
+	v.add("proxy.port", portInput)
+
+	usernameInput := widget.NewEntry()
+	usernameInput.SetPlaceHolder(lang.L("Optional"))
+	usernameInput.SetText(l.cfg.Proxy.Username)
+	usernameInput.OnChanged = func(username string) {
+		l.cfg.Proxy.Username = username
+		v.validate()
+		updateNote()
+	}
+	v.add("proxy.username", usernameInput)
+
+	passwordInput := widget.NewPasswordEntry()
+	passwordInput.SetText(l.cfg.Proxy.Password)
+	passwordInput.OnChanged = func(password string) {
+		l.cfg.Proxy.Password = password
+		v.validate()
+	}
+	v.add("proxy.password", passwordInput)
+	v.validate()
+	updateNote()
+
+	d := dialog.NewCustom(lang.L("Proxy"), lang.L("Close"),
+		container.NewVBox(
+			layout.NewSpacer(),
+			container.New(layout.NewFormLayout(),
+				widget.NewLabel(lang.L("Proxy type")), typeSelect,
+				widget.NewLabel(lang.L("Host")), hostInput,
+				widget.NewLabel(lang.L("Port")), portInput,
+				widget.NewLabel(lang.L("Username")), usernameInput,
+				widget.NewLabel(lang.L("Password")), passwordInput,
+			),
+			note,
+			v.summary,
+			layout.NewSpacer(),
+		), l.w,
+	)
+	d.Resize(fyne.NewSize(400, 200))
+	d.Show()
+}
@@ -0,0 +1,89 @@
+package tblock
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+// problems shown in the dialog, the rest is in the log
+const maxShownProblems = 15
+
+// repairInstallation checks every installed file and offers to re-download broken ones.
+// deep ignores hash cache, slower but catches files changed without touching mtime
+func (l *Launcher) repairInstallation(deep bool) {
+	if l.state != Ready {
+		return
+	}
+
+	l.settings.Hide()
+	l.setState(Downloading)
+	l.progress.Show()
+
+	d := downloader.New(l.cfg).WithLogger(l.log).WithProgress(l.onProgress)
+
+	go func() {
+		l.statusText.Set(lang.L("Verifying installation..."))
+		report, err := d.Verify(deep)
+
+		fyne.Do(func() {
+			l.progress.Hide()
+			l.statusText.Set("")
+			l.setState(Ready)
+
+			if err != nil {
+				l.showError(err)
+				return
+			}
+
+			if report.OK() {
+				dialog.ShowInformation(lang.L("Repair installation"), lang.L("All files are intact"), l.w)
+				return
+			}
+
+			confirm := dialog.NewConfirm(lang.L("Repair installation"), formatProblems(report), func(ok bool) {
+				if ok {
+					l.repair(d, report)
+				}
+			}, l.w)
+			confirm.SetConfirmText(lang.L("Repair"))
+			confirm.SetDismissText(lang.L("Cancel"))
+			confirm.Show()
+		})
+	}()
+}
+
+func (l *Launcher) repair(d *downloader.Downloader, report *downloader.VerifyReport) {
+	l.setState(Downloading)
+	l.progress.Show()
+
+	go func() {
+		l.statusText.Set(lang.L("Repairing files..."))
+		err := d.Repair(report)
+
+		fyne.Do(func() {
+			l.progress.Hide()
+			l.finishStatus(d)
+			l.setState(Ready)
+			l.showError(err)
+		})
+	}()
+}
+
+func formatProblems(report *downloader.VerifyReport) string {
+	lines := []string{lang.L("Broken files found:")}
+	for i, problem := range report.Problems {
+		if i == maxShownProblems {
+			lines = append(lines, fmt.Sprintf("... +%d", len(report.Problems)-maxShownProblems))
+			break
+		}
+
+		lines = append(lines, "• "+problem.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -12,6 +12,7 @@ import (
 	"fyne.io/fyne/v2"
 	"github.com/havrydotdev/tblock-launcher/internal/utils"
 	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
 )
 
 func getReleaseArchive() string {
@@ -39,28 +40,56 @@ func getBinaryName() string {
 	}
 }
 
+// initDirs moves legacy game folder if needed and points caches to the cache dir
+func initDirs() {
+	if err := utils.MigrateLegacyFolder(); err != nil {
+		log.Println("Failed to migrate game folder: ", err)
+	}
+
+	if dirs, err := utils.GetDirs(); err == nil {
+		downloader.SetCacheDir(dirs.Cache)
+	}
+}
+
+// ReadPersistedConfigOrDefault reads config of the instance player used last
 func ReadPersistedConfigOrDefault(app fyne.App) (*config.Config, error) {
-	gameDir, err := utils.GetTblockFolderPath()
+	gameDir, err := utils.ActiveGameDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine game folder: %s", err.Error())
 	}
 
+	return readConfigOrDefault(gameDir, app.Metadata().Version)
+}
+
+func readConfigOrDefault(gameDir, version string) (*config.Config, error) {
 	cfg, err := utils.ReadPersistedConfig(gameDir)
 	if err != nil {
 		log.Println("Failed to read config file: ", err)
 		// return default config
 		return &config.Config{
-			Username: "", GameDir: gameDir, JavaPath: utils.DefaultJavaPath,
-			Memory: utils.DefaultMemory, JvmArgs: "", Versions: config.Versions{
-				Minecraft: utils.McVersion, Launcher: app.Metadata().Version,
-				FabricLoader: utils.FabricLoaderVersion,
-			},
+			SchemaVersion: config.SchemaVersion, Username: "", GameDir: gameDir, JavaPath: utils.DefaultJavaPath,
+			Memory: utils.DefaultMemory, JvmArgs: "", Versions: recommendedVersions(config.Versions{Launcher: version}),
 		}, nil
 	}
 
 	return cfg, nil
 }
 
+// applyEnv overrides config with TBLOCK_* variables, bad values are logged and ignored.
+// Returned overrides must be restored before persisting so env values dont end up in the file
+func applyEnv(cfg *config.Config) config.Overrides {
+	overrides, err := cfg.ApplyEnv(os.LookupEnv)
+	if err != nil {
+		log.Println("Failed to apply env overrides: ", err)
+	}
+
+	for field := range overrides {
+		log.Printf("%s is overridden by %s", field, config.EnvName(field))
+	}
+
+	return overrides
+}
+
 func buildLogger(w io.Writer) *slog.Logger {
 	return slog.New(slog.NewTextHandler(w, nil))
 }
@@ -70,6 +99,9 @@ func getLogWriter(gameDir string, isDev bool) io.Writer {
 		return os.Stdout
 	}
 
+	// fresh install, nothing created the game dir yet
+	os.MkdirAll(gameDir, 0755)
+
 	file, err := os.Create(filepath.Join(gameDir, "tblock.log"))
 	if err != nil {
 		log.Println("failed to open log file: ", err)
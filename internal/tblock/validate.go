@@ -0,0 +1,153 @@
+package tblock
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/havrydotdev/tblock-launcher/internal/utils"
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+// settingsValidator shows config errors next to the entries of a dialog
+type settingsValidator struct {
+	l       *Launcher
+	entries map[string]*widget.Entry
+	// errors of all fields in this dialog, entries only show an icon
+	summary *widget.Label
+}
+
+func (l *Launcher) newSettingsValidator() *settingsValidator {
+	summary := widget.NewLabel("")
+	summary.Importance = widget.DangerImportance
+	summary.Wrapping = fyne.TextWrapWord
+	summary.Hide()
+
+	return &settingsValidator{l: l, entries: map[string]*widget.Entry{}, summary: summary}
+}
+
+// add binds entry to field at json path, fields overridden by env cant be edited
+func (v *settingsValidator) add(field string, entry *widget.Entry) {
+	entry.AlwaysShowValidationError = true
+	if v.l.env.Has(field) {
+		entry.Disable()
+	}
+
+	v.entries[field] = entry
+}
+
+// validate runs after every change, parse errors of an entry's own Validator come first
+func (v *settingsValidator) validate() {
+	fieldErrs := map[string]string{}
+	for _, err := range v.l.cfg.Validate() {
+		fieldErrs[err.Field] = err.Message
+	}
+
+	var messages []string
+	for field, entry := range v.entries {
+		var err error
+		if entry.Validator != nil {
+			err = entry.Validator(entry.Text)
+		}
+		if message, ok := fieldErrs[field]; ok && err == nil {
+			err = errors.New(lang.L(message))
+		}
+
+		entry.SetValidationError(err)
+		if err != nil {
+			messages = append(messages, lang.L(fieldLabels[field])+": "+err.Error())
+		}
+	}
+
+	if len(messages) == 0 {
+		v.summary.Hide()
+		return
+	}
+
+	// map order is random, keep the list stable while typing
+	slices.Sort(messages)
+	v.summary.SetText(strings.Join(messages, "\n"))
+	v.summary.Show()
+}
+
+// fieldLabels are the settings labels of fields, used in the error summary
+var fieldLabels = map[string]string{
+	"username":          "Username",
+	"java_path":         "Java path",
+	"memory":            "Minecraft memory",
+	"jvm_args":          "JVM arguments",
+	"download_limit":    "Download limit, KB/s",
+	"shared_cache_dir":  "Shared cache folder",
+	"shared_cache_size": "Shared cache limit, MB",
+	"proxy.host":        "Host",
+	"proxy.port":        "Port",
+	"proxy.username":    "Username",
+	"proxy.password":    "Password",
+}
+
+// fieldErrorText is a translated "<label>: <message>", fields without a label use their json path
+func fieldErrorText(err config.FieldError) string {
+	label := err.Field
+	if l, ok := fieldLabels[err.Field]; ok {
+		label = lang.L(l)
+	}
+
+	return label + ": " + lang.L(err.Message)
+}
+
+// usernameValidator checks text the way config does, so the entry shows why launch is refused
+func (l *Launcher) usernameValidator(text string) error {
+	cfg := *l.cfg
+	cfg.Username = text
+
+	var fieldErr config.FieldError
+	if errors.As(cfg.Validate().Field("username"), &fieldErr) {
+		return errors.New(lang.L(fieldErr.Message))
+	}
+
+	return nil
+}
+
+// showConfigErrors tells player about invalid values no dialog has an entry for
+// (e.g. game_dir or versions edited by hand), they need fixing in the config file
+func (l *Launcher) showConfigErrors() {
+	var lines []string
+	for _, err := range l.cfg.Validate() {
+		if _, ok := fieldLabels[err.Field]; ok {
+			continue
+		}
+
+		lines = append(lines, "• "+fieldErrorText(err))
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	text := fmt.Sprintf(lang.L("%s has invalid values, fix them in the file:"), utils.ConfigPath) + "\n" + strings.Join(lines, "\n")
+
+	d := dialog.NewInformation(lang.L("Invalid config"), text, l.w)
+	d.Resize(fyne.NewSize(450, 200))
+	d.Show()
+}
+
+// numberValidator rejects anything but a non negative integer, empty means 0
+func numberValidator(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return errors.New(lang.L("must be a whole number"))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package tblock
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/havrydotdev/tblock-launcher/internal/utils"
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+// picks the versions launcher resources are made for
+const recommendedVersion = "recommended"
+
+// recommendedVersions replaces minecraft & loader versions with the ones launcher resources are made for,
+// loader choice and the rest are kept
+func recommendedVersions(versions config.Versions) config.Versions {
+	versions.Minecraft = utils.McVersion
+	versions.FabricLoader = utils.FabricLoaderVersion
+	versions.QuiltLoader = utils.QuiltLoaderVersion
+	versions.Custom = false
+
+	return versions
+}
+
+// versionsOutdated reports whether instance runs older versions than launcher resources are made for.
+// Versions player picked never are
+func versionsOutdated(cfg *config.Config) bool {
+	return !cfg.Versions.Custom && (utils.McVersion != cfg.Versions.Minecraft || utils.FabricLoaderVersion != cfg.Versions.FabricLoader)
+}
+
+// usesLauncherResources reports whether launcher mods & static files go into the instance.
+// They are made for one version, so imported instances and versions player picked get none
+func usesLauncherResources(cfg *config.Config) bool {
+	return utils.IsMainGameDir(cfg.GameDir) && !cfg.Versions.Custom
+}
+
+// setGameVersion switches cfg to minecraft mcVersion and loaderVersion of its loader,
+// empty loaderVersion picks the newest stable one. Launcher updates keep versions set this way
+func setGameVersion(d *downloader.Downloader, cfg *config.Config, mcVersion, loaderVersion string) error {
+	loader, err := downloader.LoaderFor(cfg)
+	if err != nil {
+		return err
+	}
+
+	if loader != nil {
+		if loader.Name() == downloader.LoaderNeoForge {
+			return fmt.Errorf("picking versions is not supported for %s", loader.Name())
+		}
+
+		versions, err := d.GetLoaderVersions(loader.Name(), mcVersion)
+		if err != nil {
+			return err
+		}
+
+		i := slices.IndexFunc(versions, func(v downloader.LoaderVersion) bool {
+			if loaderVersion == "" {
+				return v.Stable
+			}
+			return v.Version == loaderVersion
+		})
+		if i < 0 {
+			return fmt.Errorf("%s %s is not available for minecraft %s", loader.Name(), loaderVersion, mcVersion)
+		}
+		loaderVersion = versions[i].Version
+
+		switch loader.Name() {
+		case downloader.LoaderFabric:
+			cfg.Versions.FabricLoader = loaderVersion
+		case downloader.LoaderQuilt:
+			cfg.Versions.QuiltLoader = loaderVersion
+		}
+	}
+
+	cfg.Versions.Minecraft = mcVersion
+	cfg.Versions.Custom = true
+
+	return nil
+}
+
+// buildVersionSelects lets player pick minecraft & loader versions instead of the recommended ones.
+// Options are fetched in background, refresh reloads loader versions after the loader changed
+func (l *Launcher) buildVersionSelects() (versionSelect, loaderSelect *widget.Select, refresh func()) {
+	recommended := fmt.Sprintf(lang.L("Recommended (%s)"), utils.McVersion)
+	d := downloader.New(l.cfg).WithLogger(l.log)
+
+	// imported instances have no recommended versions, launcher resources are not for them
+	options := []string{}
+	if utils.IsMainGameDir(l.cfg.GameDir) {
+		options = append(options, recommended)
+	}
+
+	current := recommended
+	if !usesLauncherResources(l.cfg) {
+		current = l.cfg.Versions.Minecraft
+	}
+
+	versionSelect = widget.NewSelect([]string{current}, nil)
+	versionSelect.SetSelected(current)
+
+	// label -> loader version
+	loaderVersions := map[string]string{}
+	loaderSelect = widget.NewSelect(nil, nil)
+	loaderSelect.Disable()
+
+	refresh = func() {
+		loaderSelect.Disable()
+
+		loader, err := downloader.LoaderFor(l.cfg)
+		if err != nil || loader == nil || loader.Name() == downloader.LoaderNeoForge {
+			loaderSelect.SetOptions(nil)
+			loaderSelect.ClearSelected()
+			return
+		}
+
+		mcVersion := l.cfg.Versions.Minecraft
+		go func() {
+			versions, err := d.GetLoaderVersions(loader.Name(), mcVersion)
+
+			fyne.Do(func() {
+				if err != nil {
+					l.log.Warn("failed to list loader versions", slog.String("error", err.Error()))
+					return
+				}
+
+				options := make([]string, 0, len(versions))
+				clear(loaderVersions)
+				for _, v := range versions {
+					label := v.Version
+					if !v.Stable {
+						label = fmt.Sprintf(lang.L("%s (unstable)"), v.Version)
+					}
+					loaderVersions[label] = v.Version
+					options = append(options, label)
+				}
+
+				selected := ""
+				for label, version := range loaderVersions {
+					if version == loader.Version() {
+						selected = label
+					}
+				}
+
+				handler := loaderSelect.OnChanged
+				loaderSelect.OnChanged = nil
+				loaderSelect.SetOptions(options)
+				loaderSelect.SetSelected(selected)
+				loaderSelect.OnChanged = handler
+				loaderSelect.Enable()
+			})
+		}()
+	}
+
+	// failed switches put the selects back to what cfg has
+	apply := func(mcVersion, loaderVersion string, revert func()) {
+		cfg := *l.cfg
+
+		go func() {
+			err := setGameVersion(d, &cfg, mcVersion, loaderVersion)
+
+			fyne.Do(func() {
+				if err != nil {
+					l.showError(err)
+					revert()
+					return
+				}
+
+				l.cfg.Versions = cfg.Versions
+				l.reloadCore()
+				refresh()
+			})
+		}()
+	}
+
+	versionSelect.OnChanged = func(selected string) {
+		if selected == current {
+			return
+		}
+		previous := current
+		current = selected
+
+		if selected == recommended {
+			l.cfg.Versions = recommendedVersions(l.cfg.Versions)
+			// launcher resources were skipped while versions were picked by player
+			l.cfg.Versions.Launcher = ""
+			l.reloadCore()
+			refresh()
+			return
+		}
+
+		apply(selected, "", func() {
+			current = previous
+			versionSelect.SetSelected(previous)
+		})
+	}
+
+	loaderSelect.OnChanged = func(selected string) {
+		if version := loaderVersions[selected]; version != "" {
+			apply(l.cfg.Versions.Minecraft, version, refresh)
+		}
+	}
+
+	go func() {
+		versions, err := d.GetVersions(types.VersionRelease)
+
+		fyne.Do(func() {
+			if err != nil {
+				l.log.Warn("failed to list minecraft versions", slog.String("error", err.Error()))
+				return
+			}
+
+			// fabric & quilt support the same versions, vanilla runs any
+			loader, _ := downloader.LoaderFor(l.cfg)
+			for _, v := range versions {
+				if v.Fabric || loader == nil {
+					options = append(options, v.ID)
+				}
+			}
+			if !slices.Contains(options, current) {
+				options = append(options, current)
+			}
+
+			versionSelect.SetOptions(options)
+		})
+	}()
+
+	refresh()
+	if l.cfg.Versions.Loader == downloader.LoaderNeoForge {
+		// neoforge version is tied to minecraft version of the imported modpack
+		versionSelect.Disable()
+	}
+
+	return versionSelect, loaderSelect, refresh
+}
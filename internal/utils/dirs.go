@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+const (
+	// PortableMarker next to the executable keeps all data beside it, e.g. on a usb stick
+	PortableMarker = "tblock_portable"
+	portableDir    = "data"
+	legacyDir      = ".tblock"
+	appDir         = "tblock"
+)
+
+// Dirs are folders launcher keeps its files in
+type Dirs struct {
+	// settings of the main game dir
+	Config string
+	// main game dir
+	Data string
+	// metadata cache, safe to delete
+	Cache    string
+	Portable bool
+}
+
+var (
+	dirsOnce sync.Once
+	dirs     Dirs
+	dirsErr  error
+)
+
+// GetDirs resolves folders once: beside the executable in portable mode,
+// XDG base dirs on linux and ~/.tblock everywhere else
+func GetDirs() (Dirs, error) {
+	dirsOnce.Do(func() {
+		dirs, dirsErr = resolveDirs()
+	})
+
+	return dirs, dirsErr
+}
+
+func resolveDirs() (Dirs, error) {
+	if exe, err := os.Executable(); err == nil {
+		exeDir := filepath.Dir(exe)
+		if _, err := os.Stat(filepath.Join(exeDir, PortableMarker)); err == nil {
+			data := filepath.Join(exeDir, portableDir)
+			return Dirs{Config: data, Data: data, Cache: data, Portable: true}, nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Dirs{}, err
+	}
+
+	if runtime.GOOS != "linux" {
+		legacy := filepath.Join(home, legacyDir)
+		return Dirs{Config: legacy, Data: legacy, Cache: legacy}, nil
+	}
+
+	return Dirs{
+		Config: filepath.Join(xdgDir("XDG_CONFIG_HOME", home, ".config"), appDir),
+		Data:   filepath.Join(xdgDir("XDG_DATA_HOME", home, ".local", "share"), appDir),
+		Cache:  filepath.Join(xdgDir("XDG_CACHE_HOME", home, ".cache"), appDir),
+	}, nil
+}
+
+// spec says relative values are invalid and must be ignored
+func xdgDir(env, home string, fallback ...string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(append([]string{home}, fallback...)...)
+}
+
+func GetTblockFolderPath() (string, error) {
+	dirs, err := GetDirs()
+	if err != nil {
+		return "", err
+	}
+
+	return dirs.Data, nil
+}
+
+// configDir is where config of gameDir lives. Only the main game dir uses the config dir,
+// instances (e.g. imported modpacks) keep their config beside them
+func configDir(gameDir string) string {
+	if !IsMainGameDir(gameDir) {
+		return gameDir
+	}
+
+	return dirs.Config
+}
+
+// MigrateLegacyFolder moves ~/.tblock to XDG dirs on linux, once.
+// Nothing happens in portable mode or if the new data dir already exists.
+// Must be called before anything else uses the dirs
+func MigrateLegacyFolder() error {
+	// not named dirs, the fallback below has to replace the package level one
+	resolved, err := GetDirs()
+	if err != nil || resolved.Portable || runtime.GOOS != "linux" {
+		return err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	legacy := filepath.Join(home, legacyDir)
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+
+	if _, err := os.Stat(resolved.Data); err == nil {
+		slog.Warn("both legacy and xdg game folders exist, leaving legacy one alone", slog.String("legacy", legacy), slog.String("data", resolved.Data))
+		return nil
+	}
+
+	slog.Info("moving game folder to xdg dirs", slog.String("from", legacy), slog.String("to", resolved.Data))
+
+	if err := os.MkdirAll(filepath.Dir(resolved.Data), 0755); err != nil {
+		return err
+	}
+
+	// a plain rename, copying gigabytes of assets across filesystems isnt worth it.
+	// Keep using the legacy folder then, so the game doesnt get installed twice
+	if err := os.Rename(legacy, resolved.Data); err != nil {
+		err = fmt.Errorf("failed to move %s to %s: %v", legacy, resolved.Data, err)
+		dirs = Dirs{Config: legacy, Data: legacy, Cache: legacy}
+		return err
+	}
+
+	if err := moveInto(filepath.Join(resolved.Data, "meta"), filepath.Join(resolved.Cache, "meta")); err != nil {
+		slog.Warn("failed to move metadata cache", slog.String("error", err.Error()))
+	}
+
+	for _, name := range []string{ConfigBackupPath, ConfigPath} {
+		if err := moveInto(filepath.Join(resolved.Data, name), filepath.Join(resolved.Config, name)); err != nil {
+			return fmt.Errorf("failed to move config: %v", err)
+		}
+	}
+
+	return rewriteConfigPaths(legacy, resolved.Data)
+}
+
+// moveInto renames src to dst creating parent of dst, missing src is fine
+func moveInto(src, dst string) error {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(src, dst)
+}
+
+// rewriteConfigPaths points paths inside the moved folder (game dir, downloaded java) to its new place,
+// for the main game dir and every instance in it
+func rewriteConfigPaths(from, to string) error {
+	instances, err := ListInstances()
+	if err != nil {
+		return err
+	}
+
+	for _, gameDir := range append([]string{to}, instances...) {
+		cfg, err := ReadPersistedConfig(gameDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		rewritePaths(cfg, from, to)
+		if err := PersistConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewritePaths replaces from with to in config paths inside from
+func rewritePaths(cfg *config.Config, from, to string) {
+	for _, p := range []*string{&cfg.GameDir, &cfg.JavaPath, &cfg.SharedCacheDir} {
+		if *p == from || strings.HasPrefix(*p, from+string(os.PathSeparator)) {
+			*p = to + strings.TrimPrefix(*p, from)
+		}
+	}
+}
+
+// relocatePortable points paths of a config read from gameDir at the place portable folder is now.
+// Config keeps absolute paths of wherever the folder was when it got saved
+func relocatePortable(cfg *config.Config, gameDir string) {
+	resolved, err := GetDirs()
+	if err != nil || !resolved.Portable || filepath.Clean(cfg.GameDir) == filepath.Clean(gameDir) {
+		return
+	}
+
+	// instances are <data>/instances/<name>, old data dir is what precedes that in saved game dir
+	oldData := filepath.Clean(cfg.GameDir)
+	if rel, err := filepath.Rel(resolved.Data, gameDir); err == nil && rel != "." {
+		suffix := string(os.PathSeparator) + rel
+		if !strings.HasSuffix(oldData, suffix) {
+			// instance folder got renamed as well, only its own dir is known
+			cfg.GameDir = gameDir
+			return
+		}
+		oldData = strings.TrimSuffix(oldData, suffix)
+	}
+
+	slog.Info("portable folder was moved, updating config paths", slog.String("from", oldData), slog.String("to", resolved.Data))
+	rewritePaths(cfg, oldData, resolved.Data)
+	cfg.GameDir = gameDir
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// instances created by imports live in <data>/instances/<name>
+	InstancesDir = "instances"
+	// holds game dir of the instance launcher opens on start, missing means the main one
+	ActiveInstancePath = "tblock_instance"
+)
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// NewInstanceDir picks a free folder for an instance named after name, e.g. a modpack
+func NewInstanceDir(name string) (string, error) {
+	dirs, err := GetDirs()
+	if err != nil {
+		return "", err
+	}
+
+	slug := strings.Trim(unsafeName.ReplaceAllString(name, "-"), "-.")
+	if slug == "" {
+		slug = "instance"
+	}
+
+	base := filepath.Join(dirs.Data, InstancesDir, slug)
+	dir := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			return dir, nil
+		}
+		dir = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// IsMainGameDir reports whether gameDir is the main game dir rather than an instance
+func IsMainGameDir(gameDir string) bool {
+	dirs, err := GetDirs()
+	return err == nil && filepath.Clean(gameDir) == filepath.Clean(dirs.Data)
+}
+
+// ListInstances returns game dirs of imported instances that have a config
+func ListInstances() ([]string, error) {
+	dirs, err := GetDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dirs.Data, InstancesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []string
+	for _, e := range entries {
+		dir := filepath.Join(dirs.Data, InstancesDir, e.Name())
+		if _, err := os.Stat(filepath.Join(dir, ConfigPath)); e.IsDir() && err == nil {
+			instances = append(instances, dir)
+		}
+	}
+
+	return instances, nil
+}
+
+// ActiveGameDir is the game dir launcher should open, the main one unless an instance was chosen
+func ActiveGameDir() (string, error) {
+	dirs, err := GetDirs()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dirs.Config, ActiveInstancePath))
+	if err != nil {
+		return dirs.Data, nil
+	}
+
+	// relative to data dir, so a portable install survives being moved
+	dir := filepath.Join(dirs.Data, filepath.FromSlash(strings.TrimSpace(string(data))))
+	if _, err := os.Stat(filepath.Join(dir, ConfigPath)); err != nil {
+		return dirs.Data, nil
+	}
+
+	return dir, nil
+}
+
+// SetActiveInstance makes launcher open gameDir on the next start
+func SetActiveInstance(gameDir string) error {
+	dirs, err := GetDirs()
+	if err != nil {
+		return err
+	}
+
+	pointer := filepath.Join(dirs.Config, ActiveInstancePath)
+	if IsMainGameDir(gameDir) {
+		if err := os.Remove(pointer); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	rel, err := filepath.Rel(dirs.Data, gameDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return fmt.Errorf("instance %s is outside of %s", gameDir, dirs.Data)
+	}
+
+	if err := os.MkdirAll(dirs.Config, 0755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(pointer, []byte(filepath.ToSlash(rel)))
+}
+
+// OtherGameDirs returns the main game dir and imported instances, except gameDir itself
+func OtherGameDirs(gameDir string) ([]string, error) {
+	dirs, err := GetDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	var others []string
+	for _, dir := range append([]string{dirs.Data}, instances...) {
+		if filepath.Clean(dir) != filepath.Clean(gameDir) {
+			others = append(others, dir)
+		}
+	}
+
+	return others, nil
+}
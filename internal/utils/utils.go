@@ -2,53 +2,118 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/havrydotdev/tblock-launcher/pkg/config"
 )
 
-// TODO: remove hard-coded versions
+// McVersion & loader versions are what launcher resources are made for,
+// the main instance uses them until player picks other versions
 const (
 	McVersion           = "1.21.8"
 	FabricLoaderVersion = "0.18.1"
+	QuiltLoaderVersion  = "0.29.2"
 	DefaultMemory       = "4G"
 	ConfigPath          = "tblock_settings.json"
+	ConfigBackupPath    = "tblock_settings.json.bak"
 	DefaultJavaPath     = ""
 )
 
-func GetTblockFolderPath() (string, error) {
-	home, err := os.UserHomeDir()
+// PersistConfig writes config atomically: temp file, fsync, rename.
+// Previous config is kept as a backup in case the new one turns out broken
+func PersistConfig(cfg *config.Config) error {
+	cfg.SchemaVersion = max(cfg.SchemaVersion, config.SchemaVersion)
+
+	data, err := json.Marshal(cfg)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	dir := configDir(cfg.GameDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	cfgPath := path.Join(dir, ConfigPath)
+	if current, err := os.ReadFile(cfgPath); err == nil && json.Valid(current) {
+		if err := writeFileAtomic(path.Join(dir, ConfigBackupPath), current); err != nil {
+			return fmt.Errorf("failed to back up config: %v", err)
+		}
 	}
 
-	return path.Join(home, ".tblock"), nil
+	return writeFileAtomic(cfgPath, data)
 }
 
-func PersistConfig(cfg *config.Config) error {
-	data, err := json.Marshal(cfg)
+func writeFileAtomic(filePath string, data []byte) error {
+	dir := filepath.Dir(filePath)
+	tmp, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(tmp.Name())
 
-	cfgPath := path.Join(cfg.GameDir, ConfigPath)
-	file, err := os.Create(cfgPath)
-	if err != nil {
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
 		return err
 	}
 
-	_, err = file.Write(data)
-	return err
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return err
+	}
+
+	// make the rename itself durable, not supported on windows
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
 }
 
+// ReadPersistedConfig reads & migrates config, falling back to the backup if it is broken.
+// In portable mode paths are moved along with the folder
 func ReadPersistedConfig(gameDir string) (*config.Config, error) {
-	cfgPath := path.Join(gameDir, ConfigPath)
+	dir := configDir(gameDir)
+	cfg, err := readConfigFile(path.Join(dir, ConfigPath))
+	if err == nil {
+		relocatePortable(cfg, gameDir)
+		return cfg, nil
+	}
+
+	backup, backupErr := readConfigFile(path.Join(dir, ConfigBackupPath))
+	if backupErr != nil {
+		return nil, err
+	}
+
+	slog.Warn("config is broken, using backup", slog.String("error", err.Error()))
+	relocatePortable(backup, gameDir)
+	return backup, nil
+}
+
+func readConfigFile(cfgPath string) (*config.Config, error) {
 	file, err := os.ReadFile(cfgPath)
 	if err != nil {
 		return nil, err
 	}
 
+	file, err = config.Migrate(file)
+	if err != nil {
+		return nil, err
+	}
+
 	var config config.Config
 	err = json.Unmarshal(file, &config)
 	if err != nil {
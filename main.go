@@ -2,6 +2,7 @@ package main
 
 import (
 	_ "embed"
+	"flag"
 	"fmt"
 	"log"
 
@@ -15,6 +16,70 @@ func main() {
 		}
 	}()
 
+	verify := flag.Bool("verify", false, "check installed files and exit")
+	repair := flag.Bool("repair", false, "re-download missing or corrupt files and exit")
+	deep := flag.Bool("deep", false, "rehash every file while verifying, ignoring the hash cache")
+	gc := flag.Bool("gc", false, "delete files left over from old versions and exit")
+	dryRun := flag.Bool("dry-run", false, "with -gc, only list files that would be deleted")
+	importPack := flag.String("import", "", "import a modpack (.mrpack, curseforge zip, multimc instance or its zip) into a new instance and exit")
+	enableMod := flag.String("enable-mod", "", "turn on a mod in mods folder by its jar name, e.g. sodium.jar, and exit")
+	disableMod := flag.String("disable-mod", "", "turn off a mod in mods folder by its jar name without deleting it and exit")
+	listVersions := flag.Bool("list-versions", false, "list minecraft releases and loader versions, then exit")
+	setVersion := flag.String("set-version", "", "switch to a minecraft version, or \"recommended\" for the one launcher mods are made for, and exit")
+	loaderVersion := flag.String("loader-version", "", "with -set-version, loader version to use instead of the newest stable one")
+	flag.Parse()
+
+	if *enableMod != "" && *disableMod != "" {
+		log.Fatal("use either -enable-mod or -disable-mod")
+	}
+
+	if *enableMod != "" || *disableMod != "" {
+		fileName, enabled := *disableMod, false
+		if *enableMod != "" {
+			fileName, enabled = *enableMod, true
+		}
+
+		if err := tblock.RunToggleMod(fileName, enabled); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *listVersions {
+		if err := tblock.RunListVersions(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *setVersion != "" {
+		if err := tblock.RunSetVersion(*setVersion, *loaderVersion); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *importPack != "" {
+		if err := tblock.RunImport(*importPack); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *gc {
+		if err := tblock.RunGC(*dryRun); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *verify || *repair {
+		if err := tblock.RunVerify(*repair, *deep); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	l, err := tblock.NewLauncher()
 	if err != nil {
 		log.Fatal("failed to start launcher: ", err)
@@ -1,16 +1,211 @@
 package config
 
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
 type Config struct {
-	JavaPath string   `json:"java_path"`
-	Memory   string   `json:"memory"`
-	Username string   `json:"username"`
-	GameDir  string   `json:"game_dir"`
-	JvmArgs  string   `json:"jvm_args"`
-	Versions Versions `json:"versions"`
+	// see migrate.go, 0 for configs written before versioning
+	SchemaVersion int    `json:"schema_version"`
+	JavaPath      string `json:"java_path"`
+	Memory        string `json:"memory"`
+	Username      string `json:"username"`
+	GameDir       string `json:"game_dir"`
+	JvmArgs       string `json:"jvm_args"`
+	// KB/s for all downloads together, 0 means unlimited
+	DownloadLimit int64 `json:"download_limit,omitempty"`
+	Proxy         Proxy `json:"proxy"`
+	// content addressed cache shared between instances, empty disables it
+	SharedCacheDir string `json:"shared_cache_dir,omitempty"`
+	// MB, 0 means no limit
+	SharedCacheSize int64 `json:"shared_cache_size,omitempty"`
+	// needed to import curseforge modpacks, see https://console.curseforge.com
+	CurseForgeAPIKey string   `json:"curseforge_api_key,omitempty"`
+	Versions         Versions `json:"versions"`
+
+	// fields this version doesnt know about (e.g. written by a newer launcher),
+	// kept so saving the config doesnt drop them. Unknown fields of nested
+	// objects (proxy, versions) are kept under their key as an object of only those fields
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// plainConfig has no custom (un)marshalling, avoids recursion
+type plainConfig Config
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*plainConfig)(c)); err != nil {
+		return err
+	}
+
+	extra, err := unknownFields(data, reflect.TypeOf(plainConfig{}))
+	if err != nil {
+		return err
+	}
+
+	c.Extra = nil
+	if len(extra) > 0 {
+		c.Extra = extra
+	}
+
+	return nil
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(plainConfig(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+
+	if err := mergeFields(merged, c.Extra); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merged)
+}
+
+// unknownFields returns fields of json object data that struct t has no field for.
+// Objects decoded into nested structs are checked as well
+func unknownFields(data []byte, t reflect.Type) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	for name, fieldType := range knownFields(t) {
+		value, ok := raw[name]
+		if !ok {
+			continue
+		}
+		delete(raw, name)
+
+		if fieldType.Kind() != reflect.Struct || !isObject(value) {
+			continue
+		}
+
+		nested, err := unknownFields(value, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		if len(nested) > 0 {
+			raw[name], _ = json.Marshal(nested)
+		}
+	}
+
+	return raw, nil
+}
+
+// mergeFields adds extra fields missing from dst, objects present in both are merged the same way
+func mergeFields(dst, extra map[string]json.RawMessage) error {
+	for key, value := range extra {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+
+		if !isObject(existing) || !isObject(value) {
+			continue
+		}
+
+		var existingFields, extraFields map[string]json.RawMessage
+		if err := json.Unmarshal(existing, &existingFields); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(value, &extraFields); err != nil {
+			return err
+		}
+
+		if err := mergeFields(existingFields, extraFields); err != nil {
+			return err
+		}
+
+		merged, err := json.Marshal(existingFields)
+		if err != nil {
+			return err
+		}
+		dst[key] = merged
+	}
+
+	return nil
+}
+
+func isObject(data json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return strings.HasPrefix(trimmed, "{")
+}
+
+// json names of struct t fields with their types
+func knownFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = t.Field(i).Type
+		}
+	}
+
+	return fields
+}
+
+const (
+	ProxyHTTP   = "http"
+	ProxyHTTPS  = "https"
+	ProxySOCKS5 = "socks5"
+)
+
+// Proxy is used for launcher downloads and passed to the game as well
+type Proxy struct {
+	// http, https or socks5, empty means no proxy
+	Type     string `json:"type,omitempty"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func (p Proxy) Enabled() bool {
+	return p.Type != "" && p.Host != ""
+}
+
+// URL is proxy address in the form net/http understands, nil if proxy is disabled
+func (p Proxy) URL() (*url.URL, error) {
+	if !p.Enabled() {
+		return nil, nil
+	}
+
+	switch p.Type {
+	case ProxyHTTP, ProxyHTTPS, ProxySOCKS5:
+	default:
+		return nil, fmt.Errorf("unsupported proxy type: %s", p.Type)
+	}
+
+	u := &url.URL{Scheme: p.Type, Host: net.JoinHostPort(p.Host, strconv.Itoa(p.Port))}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	return u, nil
 }
 
 type Versions struct {
-	Minecraft    string `json:"minecraft"`
-	Launcher     string `json:"launcher"`
+	Minecraft string `json:"minecraft"`
+	Launcher  string `json:"launcher"`
+	// fabric, quilt, neoforge or vanilla, empty means fabric for configs written before quilt support
+	Loader       string `json:"loader,omitempty"`
 	FabricLoader string `json:"fabric_loader"`
+	QuiltLoader  string `json:"quilt_loader,omitempty"`
+	NeoForge     string `json:"neoforge,omitempty"`
+	// player picked versions in settings, launcher updates keep them
+	Custom bool `json:"custom,omitempty"`
 }
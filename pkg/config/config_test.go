@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtraRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// fields of Extra after reading
+		extra []string
+	}{
+		{
+			name: "known fields only",
+			data: `{"schema_version": 1, "memory": "4G", "proxy": {"type": "http"}, "versions": {"minecraft": "1.21.8"}}`,
+		},
+		{
+			name:  "unknown top level field",
+			data:  `{"schema_version": 1, "memory": "4G", "theme": {"dark": true}}`,
+			extra: []string{"theme"},
+		},
+		{
+			name:  "unknown fields of nested objects",
+			data:  `{"schema_version": 1, "proxy": {"type": "socks5", "no_proxy": ["localhost"]}, "versions": {"minecraft": "1.21.8", "forge": "52.0.1"}}`,
+			extra: []string{"proxy", "versions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := json.Unmarshal([]byte(tt.data), &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(cfg.Extra) != len(tt.extra) {
+				t.Errorf("expected extra fields %v, got %v", tt.extra, cfg.Extra)
+			}
+			for _, field := range tt.extra {
+				if _, ok := cfg.Extra[field]; !ok {
+					t.Errorf("%s is missing from extra fields %v", field, cfg.Extra)
+				}
+			}
+
+			data, err := json.Marshal(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// whatever was read is written back, known fields may gain defaults
+			var original, written map[string]any
+			json.Unmarshal([]byte(tt.data), &original)
+			json.Unmarshal(data, &written)
+			assertContains(t, "", written, original)
+		})
+	}
+}
+
+// assertContains fails for every value of want missing from got, objects are compared field by field
+func assertContains(t *testing.T, path string, got, want map[string]any) {
+	t.Helper()
+
+	for key, value := range want {
+		field := path + key
+		if nested, ok := value.(map[string]any); ok {
+			gotNested, _ := got[key].(map[string]any)
+			assertContains(t, field+".", gotNested, nested)
+			continue
+		}
+
+		wantJSON, _ := json.Marshal(value)
+		gotJSON, _ := json.Marshal(got[key])
+		if string(wantJSON) != string(gotJSON) {
+			t.Errorf("%s: expected %s, got %s", field, wantJSON, gotJSON)
+		}
+	}
+}
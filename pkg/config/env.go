@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const envPrefix = "TBLOCK_"
+
+// Overrides keeps values fields had before env overrides, by json path
+type Overrides map[string]any
+
+// ApplyEnv overrides fields from TBLOCK_* variables, e.g. TBLOCK_MEMORY or
+// TBLOCK_PROXY_HOST for nested ones. lookup is usually os.LookupEnv.
+// Returned overrides let callers avoid persisting values that came from env
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) (Overrides, error) {
+	overrides := Overrides{}
+
+	var errs ValidationErrors
+	walkFields(reflect.ValueOf(c).Elem(), "", func(path string, field reflect.Value) {
+		value, ok := lookup(EnvName(path))
+		if !ok {
+			return
+		}
+
+		original := field.Interface()
+		if err := setField(field, value); err != nil {
+			errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("invalid %s: %v", EnvName(path), err)})
+			return
+		}
+
+		overrides[path] = original
+	})
+
+	if len(errs) > 0 {
+		return overrides, errs
+	}
+
+	return overrides, nil
+}
+
+// Restore returns copy of c with overridden fields set back to their original values
+func (o Overrides) Restore(c *Config) *Config {
+	restored := *c
+	walkFields(reflect.ValueOf(&restored).Elem(), "", func(path string, field reflect.Value) {
+		if original, ok := o[path]; ok {
+			field.Set(reflect.ValueOf(original))
+		}
+	})
+
+	return &restored
+}
+
+func (o Overrides) Has(path string) bool {
+	_, ok := o[path]
+	return ok
+}
+
+// EnvName is the variable overriding field at json path
+func EnvName(path string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// walkFields calls fn for every settable field with its json path, descending into nested structs.
+// schema_version and unknown fields are not settings, they are skipped
+func walkFields(v reflect.Value, prefix string, fn func(path string, field reflect.Value)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "schema_version" {
+			continue
+		}
+
+		path := prefix + name
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkFields(field, path+".", fn)
+			continue
+		}
+
+		fn(path, field)
+	}
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Kind())
+	}
+
+	return nil
+}
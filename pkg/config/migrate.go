@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the config layout this launcher writes
+const SchemaVersion = 1
+
+// migrations[i] upgrades raw config from version i to i+1. They work on raw json
+// so renamed or restructured fields can still be read
+var migrations = []func(raw map[string]json.RawMessage) error{
+	migrateLoader,
+}
+
+// Migrate upgrades persisted config to SchemaVersion. Configs from newer
+// launchers are returned as is, unknown fields survive in Config.Extra
+func Migrate(data []byte) ([]byte, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	version := 0
+	if v, ok := raw["schema_version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, fmt.Errorf("invalid schema_version: %v", err)
+		}
+	}
+
+	if version >= SchemaVersion {
+		return data, nil
+	}
+
+	for ; version < SchemaVersion; version++ {
+		if err := migrations[version](raw); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from version %d: %v", version, err)
+		}
+	}
+
+	raw["schema_version"], _ = json.Marshal(SchemaVersion)
+
+	return json.Marshal(raw)
+}
+
+// 0 -> 1: configs from before quilt support have no loader, they are all fabric
+func migrateLoader(raw map[string]json.RawMessage) error {
+	versionsData, ok := raw["versions"]
+	if !ok {
+		return nil
+	}
+
+	var versions map[string]json.RawMessage
+	if err := json.Unmarshal(versionsData, &versions); err != nil {
+		return err
+	}
+
+	var loader string
+	if v, ok := versions["loader"]; ok {
+		json.Unmarshal(v, &loader)
+	}
+
+	if loader != "" {
+		return nil
+	}
+
+	versions["loader"], _ = json.Marshal("fabric")
+
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+
+	raw["versions"] = data
+	return nil
+}
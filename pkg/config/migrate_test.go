@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// expected loader after migration, empty means versions is absent
+		loader string
+		// untouched configs come back byte for byte
+		same bool
+		err  bool
+	}{
+		{
+			name:   "unversioned config becomes fabric",
+			data:   `{"memory": "4G", "versions": {"minecraft": "1.21.8", "fabric_loader": "0.18.1"}}`,
+			loader: "fabric",
+		},
+		{
+			name:   "unversioned config keeps its loader",
+			data:   `{"versions": {"minecraft": "1.21.8", "loader": "quilt"}}`,
+			loader: "quilt",
+		},
+		{
+			name: "config without versions",
+			data: `{"memory": "4G"}`,
+		},
+		{
+			name: "current config is left as is",
+			data: `{"schema_version": 1, "versions": {"minecraft": "1.21.8"}}`,
+			same: true,
+		},
+		{
+			name: "newer config is left as is",
+			data: `{"schema_version": 99, "new_field": true}`,
+			same: true,
+		},
+		{name: "invalid schema version", data: `{"schema_version": "one"}`, err: true},
+		{name: "invalid json", data: `{`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, err := Migrate([]byte(tt.data))
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if tt.err {
+				return
+			}
+
+			if tt.same {
+				if string(migrated) != tt.data {
+					t.Errorf("expected %s unchanged, got %s", tt.data, migrated)
+				}
+				return
+			}
+
+			var cfg Config
+			if err := json.Unmarshal(migrated, &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.SchemaVersion != SchemaVersion {
+				t.Errorf("expected schema version %d, got %d", SchemaVersion, cfg.SchemaVersion)
+			}
+			if cfg.Versions.Loader != tt.loader {
+				t.Errorf("expected loader %q, got %q", tt.loader, cfg.Versions.Loader)
+			}
+			if len(cfg.Extra) != 0 {
+				t.Errorf("migration left unknown fields %v", cfg.Extra)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FieldError is a problem with a single field, Field is its json path (e.g. proxy.port)
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Field returns error for field, nil if it is fine
+func (e ValidationErrors) Field(field string) error {
+	for _, err := range e {
+		if err.Field == field {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var (
+	memoryPattern   = regexp.MustCompile(`^[1-9][0-9]*[kKmMgG]?$`)
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,16}$`)
+)
+
+// Validate checks config for values the launcher or the game wont accept.
+// Returns nil if everything is fine
+func (c *Config) Validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !memoryPattern.MatchString(c.Memory) {
+		add("memory", "must be a number with optional K, M or G suffix, e.g. 4G")
+	}
+
+	switch {
+	case c.Username == "":
+		add("username", "is required")
+	case !usernamePattern.MatchString(c.Username):
+		add("username", "must be 3-16 latin letters, digits or underscores")
+	}
+
+	if c.GameDir == "" || !filepath.IsAbs(c.GameDir) {
+		add("game_dir", "must be an absolute path")
+	}
+
+	if c.JavaPath != "" {
+		if _, err := os.Stat(c.JavaPath); err != nil {
+			add("java_path", "does not exist")
+		}
+	}
+
+	if strings.Contains(c.JvmArgs, "-Xmx") {
+		add("jvm_args", "set memory in its own field instead of -Xmx")
+	}
+
+	if c.DownloadLimit < 0 {
+		add("download_limit", "must not be negative")
+	}
+
+	if c.SharedCacheDir != "" && !filepath.IsAbs(c.SharedCacheDir) {
+		add("shared_cache_dir", "must be an absolute path")
+	}
+
+	if c.SharedCacheSize < 0 {
+		add("shared_cache_size", "must not be negative")
+	}
+
+	c.Proxy.validate(add)
+	c.Versions.validate(add)
+
+	return errs
+}
+
+func (p Proxy) validate(add func(field, format string, args ...any)) {
+	if p.Type == "" {
+		return
+	}
+
+	switch p.Type {
+	case ProxyHTTP, ProxyHTTPS, ProxySOCKS5:
+	default:
+		add("proxy.type", "must be one of http, https or socks5")
+	}
+
+	if p.Host == "" {
+		add("proxy.host", "is required")
+	}
+
+	if p.Port < 1 || p.Port > 65535 {
+		add("proxy.port", "must be between 1 and 65535")
+	}
+
+	if p.Password != "" && p.Username == "" {
+		add("proxy.username", "is required when password is set")
+	}
+}
+
+func (v Versions) validate(add func(field, format string, args ...any)) {
+	if v.Minecraft == "" {
+		add("versions.minecraft", "is required")
+	}
+
+	switch v.Loader {
+	case "", "fabric":
+		if v.FabricLoader == "" {
+			add("versions.fabric_loader", "is required for fabric")
+		}
+	case "quilt":
+		if v.QuiltLoader == "" {
+			add("versions.quilt_loader", "is required for quilt")
+		}
+	case "neoforge":
+		if v.NeoForge == "" {
+			add("versions.neoforge", "is required for neoforge")
+		}
+	case "vanilla":
+	default:
+		add("versions.loader", "must be one of fabric, quilt, neoforge or vanilla")
+	}
+}
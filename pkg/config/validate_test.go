@@ -0,0 +1,102 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	gameDir := t.TempDir()
+	valid := func() *Config {
+		return &Config{
+			Memory: "4G", Username: "player", GameDir: gameDir,
+			Versions: Versions{Minecraft: "1.21.8", Loader: "fabric", FabricLoader: "0.18.1"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		// fields with errors
+		fields []string
+	}{
+		{name: "valid config", change: func(c *Config) {}},
+		{
+			name:   "bad memory and username",
+			change: func(c *Config) { c.Memory, c.Username = "4GB", "no spaces" },
+			fields: []string{"memory", "username"},
+		},
+		{
+			name:   "missing username",
+			change: func(c *Config) { c.Username = "" },
+			fields: []string{"username"},
+		},
+		{
+			name:   "relative paths",
+			change: func(c *Config) { c.GameDir, c.SharedCacheDir = "game", "cache" },
+			fields: []string{"game_dir", "shared_cache_dir"},
+		},
+		{
+			name:   "missing java",
+			change: func(c *Config) { c.JavaPath = filepath.Join(gameDir, "missing", "java") },
+			fields: []string{"java_path"},
+		},
+		{
+			name:   "memory in jvm args",
+			change: func(c *Config) { c.JvmArgs = "-XX:+UseG1GC -Xmx8G" },
+			fields: []string{"jvm_args"},
+		},
+		{
+			name:   "negative limits",
+			change: func(c *Config) { c.DownloadLimit, c.SharedCacheSize = -1, -1 },
+			fields: []string{"download_limit", "shared_cache_size"},
+		},
+		{
+			name:   "valid proxy",
+			change: func(c *Config) { c.Proxy = Proxy{Type: ProxySOCKS5, Host: "localhost", Port: 1080} },
+		},
+		{
+			name:   "incomplete proxy",
+			change: func(c *Config) { c.Proxy = Proxy{Type: "ftp", Port: 70000, Password: "secret"} },
+			fields: []string{"proxy.type", "proxy.host", "proxy.port", "proxy.username"},
+		},
+		{
+			name:   "loader without its version",
+			change: func(c *Config) { c.Versions.Loader, c.Versions.QuiltLoader = "quilt", "" },
+			fields: []string{"versions.quilt_loader"},
+		},
+		{
+			name:   "unknown loader and no minecraft",
+			change: func(c *Config) { c.Versions.Minecraft, c.Versions.Loader = "", "forge" },
+			fields: []string{"versions.minecraft", "versions.loader"},
+		},
+		{
+			name:   "vanilla needs no loader version",
+			change: func(c *Config) { c.Versions.Loader, c.Versions.FabricLoader = "vanilla", "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.change(c)
+
+			errs := c.Validate()
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+
+			if !slices.Equal(fields, tt.fields) {
+				t.Errorf("expected errors for %v, got %v", tt.fields, errs)
+			}
+
+			for _, field := range tt.fields {
+				if errs.Field(field) == nil {
+					t.Errorf("Field(%q) returned nil", field)
+				}
+			}
+		})
+	}
+}
@@ -6,13 +6,10 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"sync"
 
 	"github.com/havrydotdev/tblock-launcher/pkg/types"
 )
 
-const ConcurrentDownloads = 10
-
 type ResourceType int
 
 const (
@@ -39,32 +36,35 @@ type AssetObject struct {
 	Size int    `json:"size"`
 }
 
-type AssetDownloadJob struct {
-	Name string
-	Hash string
-	Size int
-}
+func (d *Downloader) DownloadAssets(assets types.AssetIndex) error {
+	assetIndex, err := d.downloadAssetIndex(assets)
+	if err != nil {
+		return err
+	}
 
-type AssetDownloadResult struct {
-	Name    string
-	Error   error
-	Skipped bool
+	defer d.saveHashCache()
+
+	return d.downloadAllAssets(assetIndex)
 }
 
-func (d *Downloader) DownloadAssets(assets types.AssetIndex, onProgress ProgressCallback) error {
-	assetsDir := filepath.Join(d.cfg.GameDir, "assets")
-	indexPath := filepath.Join(assetsDir, "indexes", "5.json") // 1.21.4
+func (d *Downloader) downloadAssetIndex(assets types.AssetIndex) (*AssetIndex, error) {
+	indexPath := d.assetIndexPath(assets.ID)
 
-	if err := d.downloadWithChecksum(assets.URL, indexPath, assets.SHA1, func(downloaded, total int64) {}); err != nil {
-		return fmt.Errorf("failed to download asset index: %v", err)
+	if err := d.downloadWithChecksum(assets.URL, indexPath, assets.SHA1); err != nil {
+		return nil, fmt.Errorf("failed to download asset index: %v", err)
 	}
 
 	assetIndex, err := d.parseAssetIndex(indexPath)
 	if err != nil {
-		return fmt.Errorf("failed to parse asset index: %v", err)
+		return nil, fmt.Errorf("failed to parse asset index: %v", err)
 	}
 
-	return d.downloadAllAssets(assetIndex, onProgress)
+	return assetIndex, nil
+}
+
+// asset index is saved under its id, the game finds it by --assetIndex
+func (d *Downloader) assetIndexPath(id string) string {
+	return filepath.Join(d.getAssetsPath(), "indexes", id+".json")
 }
 
 func (d *Downloader) parseAssetIndex(indexPath string) (*AssetIndex, error) {
@@ -109,88 +109,33 @@ func (d *Downloader) parseAssetIndex(indexPath string) (*AssetIndex, error) {
 	return assetIndex, nil
 }
 
-func (d *Downloader) downloadAllAssets(assetIndex *AssetIndex, onProgress ProgressCallback) error {
-	total := len(assetIndex.Objects)
-	jobs := make(chan AssetDownloadJob, total)
-	results := make(chan AssetDownloadResult, total)
-
-	var wg sync.WaitGroup
-	for range ConcurrentDownloads {
-		wg.Add(1)
-		go func() {
-			d.assetDownloadWorker(jobs, results, &wg)
-		}()
-	}
+func (d *Downloader) downloadAllAssets(assetIndex *AssetIndex) error {
+	jobs := d.assetJobs(assetIndex)
 
-	go func() {
-		for name, obj := range assetIndex.Objects {
-			jobs <- AssetDownloadJob{
-				Name: name,
-				Hash: obj.Hash,
-				Size: obj.Size,
-			}
-		}
-		close(jobs)
-	}()
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	var downloaded, skipped, failed int
-	for result := range results {
-		if result.Error != nil {
-			d.log.Error("failed to download asset", slog.String("name", result.Name), slog.String("error", result.Error.Error()))
-			failed++
-		} else if result.Skipped {
-			skipped++
-		} else {
-			downloaded++
-		}
-
-		progress := downloaded + skipped + failed
-		if onProgress != nil {
-			onProgress(int64(progress), int64(total))
-		}
-
-		if progress%100 == 0 {
-			d.log.Info("successfully downloaded asset", slog.Int("progress", progress),
-				slog.Int("total", total), slog.Int("downloaded", downloaded),
-				slog.Int("skipped", skipped), slog.Int("failed", failed))
-		}
-	}
-
-	if failed > 0 {
-		return fmt.Errorf("%d assets failed to download", failed)
+	d.log.Info("downloading assets", slog.Int("total", len(jobs)))
+	if err := d.downloadAll(PhaseAssets, jobs); err != nil {
+		return fmt.Errorf("failed to download assets: %v", err)
 	}
 
 	return nil
 }
 
-func (d *Downloader) assetDownloadWorker(jobs <-chan AssetDownloadJob, results chan<- AssetDownloadResult, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	for job := range jobs {
-		result := AssetDownloadResult{Name: job.Name}
-
-		url := fmt.Sprintf("https://resources.download.minecraft.net/%s/%s", job.Hash[:2], job.Hash)
-		assetPath := filepath.Join(d.cfg.GameDir, "assets", "objects", job.Hash[:2], job.Hash)
+func (d *Downloader) assetJobs(assetIndex *AssetIndex) []Job {
+	jobs := make([]Job, 0, len(assetIndex.Objects))
+	for name, obj := range assetIndex.Objects {
+		jobs = append(jobs, Job{
+			Name: name, URL: assetURL(obj.Hash), Path: d.assetPath(obj.Hash),
+			SHA1: obj.Hash, Size: int64(obj.Size), Priority: PriorityNormal,
+		})
+	}
 
-		if info, err := os.Stat(assetPath); err == nil {
-			if info.Size() == int64(job.Size) {
-				if err := d.verifyChecksum(assetPath, job.Hash); err == nil {
-					result.Skipped = true
-					results <- result
-					continue
-				}
-			}
-		}
+	return jobs
+}
 
-		if err := d.downloadWithChecksum(url, assetPath, job.Hash, func(downloaded, total int64) {}); err != nil {
-			result.Error = err
-		}
+func assetURL(hash string) string {
+	return fmt.Sprintf("https://resources.download.minecraft.net/%s/%s", hash[:2], hash)
+}
 
-		results <- result
-	}
+func (d *Downloader) assetPath(hash string) string {
+	return filepath.Join(d.getAssetsPath(), "objects", hash[:2], hash)
 }
@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GameVersion is a minecraft version as shown in version pickers
+type GameVersion struct {
+	ID          string
+	Type        string
+	ReleaseTime time.Time
+	// fabric has intermediary mappings for it, so fabric loader can run
+	Fabric bool
+}
+
+type LoaderVersion struct {
+	Version string
+	Stable  bool
+}
+
+// GetVersions lists every minecraft version, newest first.
+// Empty kinds means all of them, otherwise e.g. types.VersionRelease, types.VersionSnapshot
+func (d *Downloader) GetVersions(kinds ...string) ([]GameVersion, error) {
+	manifest, err := d.GetVersionManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	fabric, err := d.getFabricGameVersions()
+	if err != nil {
+		// catalog is still useful without fabric flags
+		d.log.Warn("failed to fetch fabric game versions", slog.String("error", err.Error()))
+	}
+
+	var versions []GameVersion
+	for _, v := range manifest.Versions {
+		if len(kinds) > 0 && !slices.Contains(kinds, v.Type) {
+			continue
+		}
+
+		releaseTime, _ := time.Parse(time.RFC3339, v.ReleaseTime)
+		versions = append(versions, GameVersion{
+			ID: v.ID, Type: v.Type, ReleaseTime: releaseTime,
+			Fabric: fabric[v.ID],
+		})
+	}
+
+	return versions, nil
+}
+
+// GetLoaderVersions lists loader versions available for minecraft version, newest first
+func (d *Downloader) GetLoaderVersions(loader, mcVersion string) ([]LoaderVersion, error) {
+	var url string
+	switch loader {
+	case LoaderFabric:
+		url = "https://meta.fabricmc.net/v2/versions/loader/" + mcVersion
+	case LoaderQuilt:
+		url = "https://meta.quiltmc.org/v3/versions/loader/" + mcVersion
+	default:
+		return nil, fmt.Errorf("listing versions is not supported for %s", loader)
+	}
+
+	var entries []struct {
+		Loader struct {
+			Version string `json:"version"`
+			// quilt doesnt have it, its versions are marked with -beta instead
+			Stable *bool `json:"stable"`
+		} `json:"loader"`
+	}
+	if err := d.getJSON(url, &entries); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s versions: %v", loader, err)
+	}
+
+	versions := make([]LoaderVersion, 0, len(entries))
+	for _, e := range entries {
+		stable := !strings.Contains(e.Loader.Version, "-")
+		if e.Loader.Stable != nil {
+			stable = *e.Loader.Stable
+		}
+
+		versions = append(versions, LoaderVersion{Version: e.Loader.Version, Stable: stable})
+	}
+
+	return versions, nil
+}
+
+func (d *Downloader) getFabricGameVersions() (map[string]bool, error) {
+	var entries []struct {
+		Version string `json:"version"`
+	}
+	if err := d.getJSON("https://meta.fabricmc.net/v2/versions/game", &entries); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		versions[e.Version] = true
+	}
+
+	return versions, nil
+}
+
+func (d *Downloader) getJSON(url string, out any) error {
+	return d.fetchMetadataJSON(url, out)
+}
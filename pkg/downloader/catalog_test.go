@@ -0,0 +1,162 @@
+package downloader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+// rehost sends every request to server, host of the original url becomes the first path segment
+type rehost struct {
+	server *url.URL
+}
+
+func (r rehost) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Path = "/" + req.URL.Host + req.URL.Path
+	req.URL.Scheme, req.URL.Host = r.server.Scheme, r.server.Host
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// standIn serves mojang, fabric & quilt meta from fixed responses, paths are host + path
+func standIn(t *testing.T, responses map[string]string) *Downloader {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, _ := url.Parse(server.URL)
+	return New(&config.Config{GameDir: t.TempDir()}).WithHTTPClient(&http.Client{Transport: rehost{serverURL}})
+}
+
+const testManifest = `{"versions": [
+	{"id": "25w31a", "type": "snapshot", "releaseTime": "2025-07-30T12:00:00+00:00"},
+	{"id": "1.21.8", "type": "release", "releaseTime": "2025-07-17T12:00:00+00:00"},
+	{"id": "1.13", "type": "release", "releaseTime": "2018-07-18T15:11:46+00:00"},
+	{"id": "b1.7.3", "type": "old_beta", "releaseTime": "2011-07-07T22:00:00+00:00"}
+]}`
+
+func TestGetVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		kinds     []string
+		want      []GameVersion
+	}{
+		{
+			name: "all versions with fabric support",
+			responses: map[string]string{
+				"piston-meta.mojang.com/mc/game/version_manifest_v2.json": testManifest,
+				"meta.fabricmc.net/v2/versions/game":                      `[{"version": "25w31a"}, {"version": "1.21.8"}]`,
+			},
+			want: []GameVersion{
+				{ID: "25w31a", Type: types.VersionSnapshot, Fabric: true},
+				{ID: "1.21.8", Type: types.VersionRelease, Fabric: true},
+				{ID: "1.13", Type: types.VersionRelease},
+				{ID: "b1.7.3", Type: types.VersionOldBeta},
+			},
+		},
+		{
+			name: "only releases",
+			responses: map[string]string{
+				"piston-meta.mojang.com/mc/game/version_manifest_v2.json": testManifest,
+				"meta.fabricmc.net/v2/versions/game":                      `[{"version": "1.21.8"}]`,
+			},
+			kinds: []string{types.VersionRelease},
+			want: []GameVersion{
+				{ID: "1.21.8", Type: types.VersionRelease, Fabric: true},
+				{ID: "1.13", Type: types.VersionRelease},
+			},
+		},
+		{
+			name: "fabric meta unavailable",
+			responses: map[string]string{
+				"piston-meta.mojang.com/mc/game/version_manifest_v2.json": testManifest,
+			},
+			kinds: []string{types.VersionOldBeta},
+			want:  []GameVersion{{ID: "b1.7.3", Type: types.VersionOldBeta}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, err := standIn(t, tt.responses).GetVersions(tt.kinds...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range versions {
+				if v.ReleaseTime.IsZero() {
+					t.Errorf("%s has no release time", v.ID)
+				}
+			}
+
+			equal := func(a, b GameVersion) bool { return a.ID == b.ID && a.Type == b.Type && a.Fabric == b.Fabric }
+			if !slices.EqualFunc(versions, tt.want, equal) {
+				t.Errorf("expected %v, got %v", tt.want, versions)
+			}
+		})
+	}
+
+	if _, err := standIn(t, nil).GetVersions(); err == nil {
+		t.Error("expected error without version manifest")
+	}
+}
+
+func TestGetLoaderVersions(t *testing.T) {
+	responses := map[string]string{
+		"meta.fabricmc.net/v2/versions/loader/1.21.8": `[
+			{"loader": {"version": "0.18.2-beta.1", "stable": false}},
+			{"loader": {"version": "0.18.1", "stable": true}}
+		]`,
+		"meta.quiltmc.org/v3/versions/loader/1.21.8": `[
+			{"loader": {"version": "0.30.0-beta.1"}},
+			{"loader": {"version": "0.29.2"}}
+		]`,
+	}
+
+	tests := []struct {
+		loader    string
+		mcVersion string
+		want      []LoaderVersion
+		err       bool
+	}{
+		{
+			loader: LoaderFabric, mcVersion: "1.21.8",
+			want: []LoaderVersion{{Version: "0.18.2-beta.1"}, {Version: "0.18.1", Stable: true}},
+		},
+		{
+			loader: LoaderQuilt, mcVersion: "1.21.8",
+			want: []LoaderVersion{{Version: "0.30.0-beta.1"}, {Version: "0.29.2", Stable: true}},
+		},
+		{loader: LoaderFabric, mcVersion: "1.13", err: true},
+		{loader: LoaderNeoForge, mcVersion: "1.21.8", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.loader+" "+tt.mcVersion, func(t *testing.T) {
+			versions, err := standIn(t, responses).GetLoaderVersions(tt.loader, tt.mcVersion)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, versions)
+			}
+		})
+	}
+}
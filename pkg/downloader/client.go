@@ -5,25 +5,15 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"path"
 	"path/filepath"
 	"runtime"
 
 	"github.com/havrydotdev/tblock-launcher/pkg/types"
 )
 
-func (d *Downloader) DeleteModsAndResourcepacks() error {
-	err := os.RemoveAll(filepath.Join(d.cfg.GameDir, "mods"))
-	if err != nil {
-		return err
-	}
-
-	return os.RemoveAll(filepath.Join(d.cfg.GameDir, "resourcepacks"))
-}
-
 func (d *Downloader) DeleteVersion() error {
 	err := os.Remove(d.getClientPath())
-	if err != nil {
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
 
@@ -56,16 +46,61 @@ func (d *Downloader) getNativesPath() string {
 	return filepath.Join(d.cfg.GameDir, "natives")
 }
 
-func (d *Downloader) DownloadClient(details *types.VersionDetails, onProgress ProgressCallback) error {
-	client := details.Downloads.Client
-	clientPath := d.getClientPath()
+func (d *Downloader) DownloadClient(details *types.VersionDetails) error {
+	defer d.saveHashCache()
+
+	return d.downloadAll(PhaseClient, []Job{d.clientJob(details)})
+}
+
+// DownloadLibraries downloads libraries in parallel
+func (d *Downloader) DownloadLibraries(libraries []types.Library) error {
+	jobs := d.libraryJobs(libraries)
+
+	d.log.Info("downloading libraries", slog.Int("total", len(jobs)))
+	defer d.saveHashCache()
+
+	if err := d.downloadAll(PhaseLibraries, jobs); err != nil {
+		return fmt.Errorf("failed to download libraries: %v", err)
+	}
+
+	return nil
+}
+
+// InstallGame downloads client, libraries & assets together as one phase,
+// so they share the scheduler and progress covers their total size
+func (d *Downloader) InstallGame(details *types.VersionDetails) error {
+	// objects are listed in the index, it has to come first
+	assetIndex, err := d.downloadAssetIndex(details.AssetIndex)
+	if err != nil {
+		return err
+	}
+
+	jobs := append([]Job{d.clientJob(details)}, d.libraryJobs(details.Libraries)...)
+	jobs = append(jobs, d.assetJobs(assetIndex)...)
+
+	d.log.Info("downloading game files", slog.Int("total", len(jobs)))
+	defer d.saveHashCache()
+
+	if err := d.downloadAll(PhaseGame, jobs); err != nil {
+		return fmt.Errorf("failed to download minecraft: %v", err)
+	}
+
+	return nil
+}
 
-	return d.downloadWithChecksum(client.URL, clientPath, client.SHA1, onProgress)
+func (d *Downloader) clientJob(details *types.VersionDetails) Job {
+	client := details.Downloads.Client
+	return Job{
+		Name: "minecraft.jar", URL: client.URL, Path: d.getClientPath(),
+		SHA1: client.SHA1, Size: int64(client.Size), Priority: PriorityNormal,
+	}
 }
 
-func (d *Downloader) DownloadLibraries(libraries []types.Library, onProgress ProgressCallback) error {
+func (d *Downloader) libraryJobs(libraries []types.Library) []Job {
 	librariesPath := d.getLibrariesPath()
-	for i, library := range libraries {
+
+	var jobs []Job
+	for _, library := range libraries {
 		if !d.shouldDownloadLibrary(library) {
 			continue
 		}
@@ -81,48 +116,13 @@ func (d *Downloader) DownloadLibraries(libraries []types.Library, onProgress Pro
 			continue
 		}
 
-		libraryPath := filepath.Join(librariesPath, artifact.Path)
-
-		d.log.Info("downloading library", slog.Int("progress", i+1), slog.Int("total", len(libraries)), slog.String("name", artifact.Path))
-		onProgress(int64(i+1), int64(len(libraries)))
-
-		if err := d.downloadWithChecksum(artifact.URL, libraryPath, artifact.SHA1, func(downloaded, total int64) {}); err != nil {
-			return fmt.Errorf("failed to download library %s: %v", library.Name, err)
-		}
+		jobs = append(jobs, Job{
+			Name: library.Name, URL: artifact.URL, Path: filepath.Join(librariesPath, artifact.Path),
+			SHA1: artifact.SHA1, Size: int64(artifact.Size), Priority: PriorityHigh,
+		})
 	}
 
-	return nil
-}
-
-// dowloads mods & resoucepacks
-func (d *Downloader) DownloadResouces(resources []ResouceData) error {
-	modsDir := path.Join(d.cfg.GameDir, "mods")
-	err := os.Mkdir(modsDir, 0755)
-	if err != nil && !errors.Is(err, os.ErrExist) {
-		return err
-	}
-
-	packsDir := path.Join(d.cfg.GameDir, "resourcepacks")
-	err = os.Mkdir(packsDir, 0755)
-	if err != nil && !errors.Is(err, os.ErrExist) {
-		return err
-	}
-
-	paths := map[ResourceType]string{
-		Mod:          modsDir,
-		ResourcePack: packsDir,
-	}
-
-	for _, r := range resources {
-		dir := paths[r.Type]
-		name := path.Base(r.URL)
-		err := d.download(r.URL, path.Join(dir, name), func(downloaded, total int64) {})
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return jobs
 }
 
 func mcRuleToOs(mcOs string) string {
@@ -133,15 +133,20 @@ func mcRuleToOs(mcOs string) string {
 	return mcOs
 }
 
+func (d *Downloader) shouldDownloadLibrary(library types.Library) bool {
+	return LibraryAllowed(library)
+}
+
+// LibraryAllowed reports whether library is needed on this os
 // TODO rules actually have a lot more conditions
 // also, when i figure this out launcher itself wont be so 1.21.8 dependent
-func (d *Downloader) shouldDownloadLibrary(library types.Library) bool {
+func LibraryAllowed(library types.Library) bool {
 	if len(library.Rules) == 0 {
 		return true
 	}
 
 	for _, rule := range library.Rules {
-		if rule.Action == "allow" && runtime.GOOS != mcRuleToOs(rule.OS.Name) {
+		if rule.Action == "allow" && rule.OS != nil && runtime.GOOS != mcRuleToOs(rule.OS.Name) {
 			return false
 		}
 	}
@@ -152,14 +157,12 @@ func (d *Downloader) shouldDownloadLibrary(library types.Library) bool {
 // TODO host them on cdn?
 func (d *Downloader) WriteOverrides(overrides []StaticAsset) error {
 	for _, s := range overrides {
-		filePath := path.Join(d.cfg.GameDir, s.Path)
-		file, err := os.Create(filePath)
-		if err != nil {
+		filePath := filepath.Join(d.cfg.GameDir, filepath.FromSlash(s.Path))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 			return err
 		}
 
-		_, err = file.Write(s.Data)
-		if err != nil {
+		if err := os.WriteFile(filePath, s.Data, 0644); err != nil {
 			return err
 		}
 	}
@@ -8,21 +8,25 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
-	"strings"
+	"path/filepath"
+	"sync/atomic"
 
 	"github.com/havrydotdev/tblock-launcher/pkg/config"
 )
 
 type Downloader struct {
-	client *http.Client
-	cfg    *config.Config
-	log    *slog.Logger
+	client  *http.Client
+	cfg     *config.Config
+	log     *slog.Logger
+	offline atomic.Bool
+
+	sched      *Scheduler
+	limiter    *RateLimiter
+	onProgress ProgressFunc
 }
 
-type ProgressCallback func(downloaded, total int64)
-
 func New(cfg *config.Config) *Downloader {
-	return &Downloader{cfg: cfg, client: http.DefaultClient, log: slog.Default()}
+	return &Downloader{cfg: cfg, client: defaultHTTPClient(cfg), log: slog.Default(), sched: defaultScheduler, limiter: defaultRateLimiter}
 }
 
 func (d *Downloader) WithHTTPClient(client *http.Client) *Downloader {
@@ -35,95 +39,150 @@ func (d *Downloader) WithLogger(log *slog.Logger) *Downloader {
 	return d
 }
 
-// TODO
-func (d *Downloader) download(url, filepath string, onProgress ProgressCallback) error {
-	if err := os.MkdirAll(filepath[:strings.LastIndex(filepath, string(os.PathSeparator))], 0755); err != nil {
+func (d *Downloader) WithScheduler(sched *Scheduler) *Downloader {
+	d.sched = sched
+	return d
+}
+
+func (d *Downloader) download(url, filepath string) error {
+	return d.downloadJob(Job{URL: url, Path: filepath, Priority: PriorityNormal}, nil)
+}
+
+func (d *Downloader) downloadWithChecksum(url, filepath, expectedSHA1 string) error {
+	return d.downloadJob(Job{URL: url, Path: filepath, SHA1: expectedSHA1, Priority: PriorityNormal}, nil)
+}
+
+// downloadJob downloads a single file once scheduler lets it.
+// Existing file is kept if it matches the checksum (or there is none to check).
+// Progress goes to t, which may be nil
+func (d *Downloader) downloadJob(job Job, t *progressTracker) error {
+	if job.Name == "" {
+		job.Name = job.Path
+	}
+
+	if err := os.MkdirAll(filepath.Dir(job.Path), 0755); err != nil {
 		return err
 	}
 
-	if info, err := os.Stat(filepath); err == nil && info.Size() > 0 {
-		d.log.Warn("file already exists", slog.String("path", filepath))
+	if info, err := os.Stat(job.Path); err == nil && info.Size() > 0 {
+		if job.SHA1 == "" || d.verifyChecksum(job.Path, job.SHA1) == nil {
+			t.skip(job.Name, job.Size)
+			return nil
+		}
+
+		d.log.Warn("existing file is corrupt, downloading again", slog.String("path", job.Path))
+	}
+
+	if d.linkFromSharedCache(job) {
+		d.storeSHA1(job.Path, job.SHA1)
+		t.skip(job.Name, job.Size)
 		return nil
 	}
 
-	resp, err := d.client.Get(url)
+	release := d.sched.acquire(job.URL, job.Priority, job.Size)
+	defer release()
+
+	resp, err := d.client.Get(job.URL)
 	if err != nil {
-		return fmt.Errorf("failed to download %s: %v", url, err)
+		return fmt.Errorf("failed to download %s: %v", job.URL, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("error status for %s: %s", url, resp.Status)
+		return fmt.Errorf("error status for %s: %s", job.URL, resp.Status)
 	}
 
-	tmpPath := filepath + ".tmp"
+	tmpPath := job.Path + ".tmp"
 	out, err := os.Create(tmpPath)
 	if err != nil {
 		return err
 	}
 
-	downloaded := 0
-	total := resp.ContentLength
+	if job.Size == 0 && resp.ContentLength > 0 {
+		t.addTotal(resp.ContentLength)
+	}
+
 	buffer := make([]byte, 32*1024)
 	for {
 		n, err := resp.Body.Read(buffer)
 		if n > 0 {
 			if _, writeErr := out.Write(buffer[:n]); writeErr != nil {
+				out.Close()
 				return writeErr
 			}
 		}
+
+		t.addBytes(int64(n))
+		d.limiter.wait(n)
+
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
+			out.Close()
 			return err
 		}
+	}
 
-		downloaded += n
-
-		onProgress(int64(downloaded), total)
+	if err := out.Close(); err != nil {
+		return err
 	}
 
-	out.Close()
+	if job.SHA1 != "" {
+		actualSHA1, err := FileSHA1(tmpPath)
+		if err != nil {
+			return err
+		}
+
+		if actualSHA1 != job.SHA1 {
+			os.Remove(tmpPath)
+			return fmt.Errorf("checksum verification failed for %s: expected %s, got %s", job.Path, job.SHA1, actualSHA1)
+		}
+	}
 
-	if err := os.Rename(tmpPath, filepath); err != nil {
+	if err := os.Rename(tmpPath, job.Path); err != nil {
 		return err
 	}
 
+	if job.SHA1 != "" {
+		d.storeSHA1(job.Path, job.SHA1)
+		d.addToSharedCache(job.Path, job.SHA1)
+	}
+
+	t.fileDone(job.Name)
 	return nil
 }
 
-func (d *Downloader) downloadWithChecksum(url, filepath, expectedSHA1 string, onProgress ProgressCallback) error {
-	if err := d.download(url, filepath, onProgress); err != nil {
+func (d *Downloader) verifyChecksum(filepath, expectedSHA1 string) error {
+	actualSHA1, err := d.cachedSHA1(filepath)
+	if err != nil {
 		return err
 	}
 
-	if expectedSHA1 != "" {
-		if err := d.verifyChecksum(filepath, expectedSHA1); err != nil {
-			os.Remove(filepath)
-			return fmt.Errorf("checksum verification failed for %s: %v", filepath, err)
-		}
+	if actualSHA1 != expectedSHA1 {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSHA1, actualSHA1)
 	}
 
 	return nil
 }
 
-func (d *Downloader) verifyChecksum(filepath, expectedSHA1 string) error {
+func FileSHA1(filepath string) (string, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer file.Close()
 
 	hasher := sha1.New()
 	if _, err := io.Copy(hasher, file); err != nil {
-		return err
+		return "", err
 	}
 
-	actualSHA1 := hex.EncodeToString(hasher.Sum(nil))
-	if actualSHA1 != expectedSHA1 {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSHA1, actualSHA1)
-	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
 
-	return nil
+// DownloadFile downloads url to dest and verifies it against sha1 if it is not empty.
+// Existing file at dest is kept as is
+func (d *Downloader) DownloadFile(url, dest, sha1 string) error {
+	return d.downloadWithChecksum(url, dest, sha1)
 }
@@ -0,0 +1,375 @@
+package downloader
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type UsageCategory string
+
+const (
+	UsageLibraries UsageCategory = "libraries"
+	UsageAssets    UsageCategory = "assets/objects"
+	UsageVersions  UsageCategory = "versions"
+)
+
+// CategoryUsage is disk usage of one folder of the game dir
+type CategoryUsage struct {
+	Category    UsageCategory
+	Total       int64
+	Reclaimable int64
+	// files (or version folders) nothing refers to anymore
+	Orphans []string
+}
+
+type UsageReport struct {
+	Categories []CategoryUsage
+}
+
+func (r *UsageReport) Reclaimable() int64 {
+	var total int64
+	for _, c := range r.Categories {
+		total += c.Reclaimable
+	}
+
+	return total
+}
+
+func (r *UsageReport) Orphans() []string {
+	var orphans []string
+	for _, c := range r.Categories {
+		orphans = append(orphans, c.Orphans...)
+	}
+
+	return orphans
+}
+
+// DiskUsage finds files no installed version or loader refers to anymore.
+// Profiles in gameDirs (e.g. other instances) are taken into account too.
+// Nothing is deleted, pass the report to CollectGarbage for that
+func (d *Downloader) DiskUsage(gameDirs ...string) (*UsageReport, error) {
+	refs, err := d.referencedFiles(gameDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	libraries, err := d.scanOrphans(UsageLibraries, d.getLibrariesPath(), refs.libraries)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{Categories: []CategoryUsage{libraries}}
+
+	// without an index we cant tell which objects are used, keep them all
+	if refs.assets != nil {
+		assets, err := d.scanOrphans(UsageAssets, filepath.Join(d.getAssetsPath(), "objects"), refs.assets)
+		if err != nil {
+			return nil, err
+		}
+		report.Categories = append(report.Categories, assets)
+	}
+
+	versions, err := d.scanVersions(refs.versions)
+	if err != nil {
+		return nil, err
+	}
+	report.Categories = append(report.Categories, versions)
+
+	return report, nil
+}
+
+// CollectGarbage deletes orphans listed in report and returns freed bytes
+func (d *Downloader) CollectGarbage(report *UsageReport) (int64, error) {
+	gameDir := filepath.Clean(d.cfg.GameDir) + string(os.PathSeparator)
+
+	var freed int64
+	for _, c := range report.Categories {
+		for _, orphan := range c.Orphans {
+			if !strings.HasPrefix(orphan, gameDir) {
+				return freed, fmt.Errorf("refusing to delete %s outside of game dir", orphan)
+			}
+
+			size, err := pathSize(orphan)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return freed, err
+			}
+
+			d.log.Info("deleting orphaned file", slog.String("path", orphan))
+			if err := os.RemoveAll(orphan); err != nil {
+				return freed, err
+			}
+			freed += size
+		}
+	}
+
+	removeEmptyDirs(d.getLibrariesPath())
+	removeEmptyDirs(filepath.Join(d.getAssetsPath(), "objects"))
+
+	return freed, nil
+}
+
+type referenced struct {
+	// absolute paths
+	libraries map[string]bool
+	// nil if some asset index is missing
+	assets map[string]bool
+	// version ids
+	versions map[string]bool
+}
+
+// gcProfile has what gc needs from both vanilla and loader version jsons
+type gcProfile struct {
+	ID           string `json:"id"`
+	InheritsFrom string `json:"inheritsFrom"`
+	AssetIndex   struct {
+		ID string `json:"id"`
+	} `json:"assetIndex"`
+	Libraries []LoaderLibrary `json:"libraries"`
+}
+
+// referencedFiles resolves every profile under versions dir of the game dir and of gameDirs,
+// so switching loader or version doesnt make the previous one garbage
+func (d *Downloader) referencedFiles(gameDirs []string) (*referenced, error) {
+	// refuse to touch anything when we dont know what is installed
+	if _, err := d.ReadVersionDetails(d.cfg.Versions.Minecraft); err != nil {
+		return nil, fmt.Errorf("failed to read installed version: %v", err)
+	}
+
+	refs := &referenced{libraries: map[string]bool{}, assets: map[string]bool{}, versions: map[string]bool{}}
+	for _, gameDir := range append([]string{d.cfg.GameDir}, gameDirs...) {
+		if err := d.referenceGameDir(gameDir, refs); err != nil {
+			return nil, err
+		}
+	}
+
+	return refs, nil
+}
+
+func (d *Downloader) referenceGameDir(gameDir string, refs *referenced) error {
+	profiles, err := readProfiles(filepath.Join(gameDir, "versions"))
+	if err != nil {
+		return err
+	}
+
+	own := filepath.Clean(gameDir) == filepath.Clean(d.cfg.GameDir)
+	for name := range profiles {
+		if own {
+			refs.versions[name] = true
+		}
+
+		// loader profiles take asset index & libraries from the version they inherit from
+		seen := map[string]bool{}
+		for profile := profiles[name]; profile != nil && !seen[profile.ID]; profile = profiles[profile.InheritsFrom] {
+			seen[profile.ID] = true
+			for _, library := range profile.Libraries {
+				refs.libraries[filepath.Join(d.getLibrariesPath(), filepath.FromSlash(library.Path()))] = true
+			}
+
+			if profile.AssetIndex.ID != "" {
+				d.referenceAssetIndex(filepath.Join(gameDir, "assets", "indexes", profile.AssetIndex.ID+".json"), refs)
+			}
+
+			if version, ok := strings.CutPrefix(profile.ID, "neoforge-"); ok {
+				if err := d.referenceNeoForgeInstaller(NewNeoForgeLoader(profile.InheritsFrom, version), refs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// readProfiles reads <name>/<name>.json of every version folder, keyed by name.
+// Folders without a readable profile are left out, they are orphans
+func readProfiles(versionsDir string) (map[string]*gcProfile, error) {
+	entries, err := os.ReadDir(versionsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := map[string]*gcProfile{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(versionsDir, entry.Name(), entry.Name()+".json"))
+		if err != nil {
+			continue
+		}
+
+		var profile gcProfile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			continue
+		}
+
+		profile.ID = entry.Name()
+		profiles[entry.Name()] = &profile
+	}
+
+	return profiles, nil
+}
+
+// without an index we cant tell which objects are used, then all of them are kept
+func (d *Downloader) referenceAssetIndex(indexPath string, refs *referenced) {
+	if refs.assets == nil {
+		return
+	}
+
+	assetIndex, err := d.parseAssetIndex(indexPath)
+	if err != nil {
+		refs.assets = nil
+		return
+	}
+
+	for _, obj := range assetIndex.Objects {
+		refs.assets[d.assetPath(obj.Hash)] = true
+	}
+}
+
+// installer, processor tools and their outputs are kept so reinstalling doesnt redo everything
+func (d *Downloader) referenceNeoForgeInstaller(n *NeoForgeLoader, refs *referenced) error {
+	installerPath := d.libraryPath(n.installerCoord())
+	refs.libraries[installerPath] = true
+
+	installer, err := zip.OpenReader(installerPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer installer.Close()
+
+	var profile neoForgeInstallProfile
+	if err := readZipJSON(&installer.Reader, "install_profile.json", &profile); err != nil {
+		return err
+	}
+
+	for _, library := range profile.Libraries {
+		path := library.Downloads.Artifact.Path
+		if path == "" {
+			path = parseMavenCoord(library.Name).path()
+		}
+		refs.libraries[filepath.Join(d.getLibrariesPath(), filepath.FromSlash(path))] = true
+	}
+
+	for _, data := range profile.Data {
+		if strings.HasPrefix(data.Client, "[") && strings.HasSuffix(data.Client, "]") {
+			refs.libraries[d.libraryPath(strings.Trim(data.Client, "[]"))] = true
+		}
+	}
+
+	return nil
+}
+
+func (d *Downloader) scanOrphans(category UsageCategory, root string, refs map[string]bool) (CategoryUsage, error) {
+	usage := CategoryUsage{Category: category}
+
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		usage.Total += info.Size()
+		if !refs[p] {
+			usage.Reclaimable += info.Size()
+			usage.Orphans = append(usage.Orphans, p)
+		}
+
+		return nil
+	})
+
+	return usage, err
+}
+
+// whole version folders are orphans, they only contain that version's files
+func (d *Downloader) scanVersions(keep map[string]bool) (CategoryUsage, error) {
+	usage := CategoryUsage{Category: UsageVersions}
+
+	versionsDir := filepath.Join(d.cfg.GameDir, "versions")
+	entries, err := os.ReadDir(versionsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return usage, nil
+	}
+	if err != nil {
+		return usage, err
+	}
+
+	for _, entry := range entries {
+		p := filepath.Join(versionsDir, entry.Name())
+		size, err := pathSize(p)
+		if err != nil {
+			return usage, err
+		}
+
+		usage.Total += size
+		if !entry.IsDir() || !keep[entry.Name()] {
+			usage.Reclaimable += size
+			usage.Orphans = append(usage.Orphans, p)
+		}
+	}
+
+	return usage, nil
+}
+
+func pathSize(p string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(p, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+		return nil
+	})
+
+	return size, err
+}
+
+// removeEmptyDirs cleans up folders left empty after deleting files, root itself stays
+func removeEmptyDirs(root string) {
+	var dirs []string
+	filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() && p != root {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+
+	// deepest first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+}
@@ -0,0 +1,111 @@
+package downloader
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+func TestDiskUsage(t *testing.T) {
+	const vanilla = `{"id": "1.21.8", "assetIndex": {"id": "26"}, "libraries": [
+		{"name": "com.mojang:brigadier:1.3.10", "downloads": {"artifact": {"path": "com/mojang/brigadier/1.3.10/brigadier-1.3.10.jar"}}}
+	]}`
+	const index = `{"objects": {"icon.png": {"hash": "aabb", "size": 1}}}`
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		// game dir of another instance, path -> content
+		instance map[string]string
+		// relative to game dir
+		orphans []string
+	}{
+		{
+			name: "libraries of every installed loader are kept",
+			files: map[string]string{
+				"versions/fabric-loader-0.18.1-1.21.8/fabric-loader-0.18.1-1.21.8.json": `{"inheritsFrom": "1.21.8", "libraries": [{"name": "net.fabricmc:fabric-loader:0.18.1"}]}`,
+				"versions/quilt-loader-0.29.2-1.21.8/quilt-loader-0.29.2-1.21.8.json":   `{"inheritsFrom": "1.21.8", "libraries": [{"name": "org.quiltmc:quilt-loader:0.29.2"}]}`,
+				"libraries/net/fabricmc/fabric-loader/0.18.1/fabric-loader-0.18.1.jar":  "fabric",
+				"libraries/org/quiltmc/quilt-loader/0.29.2/quilt-loader-0.29.2.jar":     "quilt",
+				"libraries/org/old/old/1.0/old-1.0.jar":                                 "old",
+			},
+			orphans: []string{"libraries/org/old/old/1.0/old-1.0.jar"},
+		},
+		{
+			name: "version folder without a profile is an orphan",
+			files: map[string]string{
+				"versions/1.20.1/minecraft.jar": "partial",
+			},
+			orphans: []string{"versions/1.20.1"},
+		},
+		{
+			name: "assets of another installed version are kept",
+			files: map[string]string{
+				"versions/1.20.1/1.20.1.json": `{"id": "1.20.1", "assetIndex": {"id": "5"}}`,
+				"assets/indexes/5.json":       `{"objects": {"old.png": {"hash": "ccdd", "size": 1}}}`,
+				"assets/objects/cc/ccdd":      "old",
+				"assets/objects/ee/eeff":      "unused",
+			},
+			orphans: []string{"assets/objects/ee/eeff"},
+		},
+		{
+			name: "missing asset index keeps every object",
+			files: map[string]string{
+				"versions/1.20.1/1.20.1.json": `{"id": "1.20.1", "assetIndex": {"id": "5"}}`,
+				"assets/objects/ee/eeff":      "unknown",
+			},
+		},
+		{
+			name: "profiles of other instances are kept",
+			files: map[string]string{
+				"libraries/org/shared/shared/1.0/shared-1.0.jar": "shared",
+			},
+			instance: map[string]string{
+				"versions/1.20.1/1.20.1.json": `{"id": "1.20.1", "libraries": [{"name": "org.shared:shared:1.0"}]}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameDir := t.TempDir()
+			writeFile(t, gameDir, "versions/1.21.8/1.21.8.json", vanilla)
+			writeFile(t, gameDir, "versions/1.21.8/minecraft.jar", "client")
+			writeFile(t, gameDir, "libraries/com/mojang/brigadier/1.3.10/brigadier-1.3.10.jar", "brigadier")
+			writeFile(t, gameDir, "assets/indexes/26.json", index)
+			writeFile(t, gameDir, "assets/objects/aa/aabb", "icon")
+			for p, content := range tt.files {
+				writeFile(t, gameDir, p, content)
+			}
+
+			var others []string
+			if tt.instance != nil {
+				instanceDir := t.TempDir()
+				for p, content := range tt.instance {
+					writeFile(t, instanceDir, p, content)
+				}
+				others = append(others, instanceDir)
+			}
+
+			d := New(&config.Config{GameDir: gameDir, Versions: config.Versions{Minecraft: "1.21.8", Loader: LoaderFabric}})
+			report, err := d.DiskUsage(others...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []string
+			for _, p := range tt.orphans {
+				want = append(want, filepath.Join(gameDir, filepath.FromSlash(p)))
+			}
+
+			got := report.Orphans()
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("expected orphans %q, got %q", want, got)
+			}
+		})
+	}
+}
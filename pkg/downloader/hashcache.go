@@ -0,0 +1,179 @@
+package downloader
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// HashCachePath stores sha1 of files we already hashed, so unchanged
+// assets & libraries dont have to be read again on every install or verify
+const HashCachePath = "tblock_hashes.bin"
+
+type hashEntry struct {
+	Size    int64
+	ModTime int64
+	SHA1    [20]byte
+}
+
+// hashCache is keyed by path relative to game dir. Entry is valid
+// only while file size & mtime are the same as when it was hashed
+type hashCache struct {
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]hashEntry
+	dirty   bool
+}
+
+var (
+	hashCachesMu sync.Mutex
+	hashCaches   = map[string]*hashCache{}
+)
+
+// one cache per game dir, so downloaders running at the same time (install, repair...)
+// dont overwrite each other's entries when they save
+func (d *Downloader) hashes() *hashCache {
+	gameDir := filepath.Clean(d.cfg.GameDir)
+
+	hashCachesMu.Lock()
+	cache, ok := hashCaches[gameDir]
+	if !ok {
+		cache = &hashCache{entries: map[string]hashEntry{}}
+		hashCaches[gameDir] = cache
+	}
+	hashCachesMu.Unlock()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.loaded {
+		return cache
+	}
+	cache.loaded = true
+
+	file, err := os.Open(filepath.Join(gameDir, HashCachePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return cache
+	}
+	if err != nil {
+		d.log.Warn("failed to open hash cache", slog.String("error", err.Error()))
+		return cache
+	}
+	defer file.Close()
+
+	if err := gob.NewDecoder(file).Decode(&cache.entries); err != nil {
+		d.log.Warn("hash cache is corrupt, starting over", slog.String("error", err.Error()))
+		cache.entries = map[string]hashEntry{}
+	}
+
+	return cache
+}
+
+func (d *Downloader) hashKey(path string) string {
+	if rel, err := filepath.Rel(d.cfg.GameDir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(path)
+}
+
+// cachedSHA1 is FileSHA1 that skips reading files that didnt change since last time
+func (d *Downloader) cachedSHA1(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	cache := d.hashes()
+	key := d.hashKey(path)
+
+	cache.mu.Lock()
+	entry, ok := cache.entries[key]
+	cache.mu.Unlock()
+
+	if ok && entry.Size == info.Size() && entry.ModTime == info.ModTime().UnixNano() {
+		return hex.EncodeToString(entry.SHA1[:]), nil
+	}
+
+	hash, err := FileSHA1(path)
+	if err != nil {
+		return "", err
+	}
+
+	entry = hashEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	if _, err := hex.Decode(entry.SHA1[:], []byte(hash)); err != nil {
+		return "", err
+	}
+
+	cache.mu.Lock()
+	cache.entries[key] = entry
+	cache.dirty = true
+	cache.mu.Unlock()
+
+	return hash, nil
+}
+
+// storeSHA1 remembers hash of a file we just wrote and already checked
+func (d *Downloader) storeSHA1(path, hash string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	entry := hashEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	if _, err := hex.Decode(entry.SHA1[:], []byte(hash)); err != nil {
+		return
+	}
+
+	cache := d.hashes()
+	cache.mu.Lock()
+	cache.entries[d.hashKey(path)] = entry
+	cache.dirty = true
+	cache.mu.Unlock()
+}
+
+// saveHashCache writes cache to disk if anything was hashed since it was loaded
+func (d *Downloader) saveHashCache() {
+	cache := d.hashes()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if !cache.dirty {
+		return
+	}
+
+	// drop files that are gone
+	for key := range cache.entries {
+		if _, err := os.Stat(filepath.Join(d.cfg.GameDir, filepath.FromSlash(key))); err != nil {
+			delete(cache.entries, key)
+		}
+	}
+
+	cachePath := filepath.Join(d.cfg.GameDir, HashCachePath)
+	tmpPath := cachePath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		d.log.Warn("failed to save hash cache", slog.String("error", err.Error()))
+		return
+	}
+
+	err = gob.NewEncoder(file).Encode(cache.entries)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, cachePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		d.log.Warn("failed to save hash cache", slog.String("error", err.Error()))
+		return
+	}
+
+	cache.dirty = false
+}
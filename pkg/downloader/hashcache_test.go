@@ -0,0 +1,39 @@
+package downloader
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+func TestHashCacheSharedBetweenDownloaders(t *testing.T) {
+	gameDir := t.TempDir()
+	writeFile(t, gameDir, "libraries/a.jar", "a")
+	writeFile(t, gameDir, "libraries/b.jar", "b")
+
+	// e.g. install and repair running at the same time
+	install := New(&config.Config{GameDir: gameDir})
+	repair := New(&config.Config{GameDir: gameDir})
+
+	if _, err := install.cachedSHA1(filepath.Join(gameDir, "libraries", "a.jar")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repair.cachedSHA1(filepath.Join(gameDir, "libraries", "b.jar")); err != nil {
+		t.Fatal(err)
+	}
+	install.saveHashCache()
+	repair.saveHashCache()
+
+	// as if the launcher started again
+	hashCachesMu.Lock()
+	delete(hashCaches, filepath.Clean(gameDir))
+	hashCachesMu.Unlock()
+
+	entries := New(&config.Config{GameDir: gameDir}).hashes().entries
+	for _, key := range []string{"libraries/a.jar", "libraries/b.jar"} {
+		if _, ok := entries[key]; !ok {
+			t.Errorf("%s is missing from saved cache: %v", key, entries)
+		}
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
-	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -20,6 +21,8 @@ import (
 const (
 	JavaVersion = "21"
 	JavaRelease = "21.0.9+10"
+	// sizes & hashes of extracted java files
+	javaManifest = "tblock_java_files.json"
 )
 
 // TODO add graalvm and see if its decreasing start up times
@@ -42,6 +45,16 @@ func (d *Downloader) GetJavaPath() string {
 	return filepath.Join(javaBaseFolder, "bin", "java")
 }
 
+// JavaExecutable is the java binary inside configured java folder
+func JavaExecutable(javaPath string) string {
+	javaBinary := "java"
+	if runtime.GOOS == "windows" {
+		javaBinary = "java.exe"
+	}
+
+	return filepath.Join(javaPath, javaBinary)
+}
+
 func (d *Downloader) DownloadJava() error {
 	javaURL := d.getJavaDownloadURL()
 	d.log.Info("downloading java", slog.String("url", javaURL))
@@ -58,9 +71,77 @@ func (d *Downloader) DownloadJava() error {
 		return err
 	}
 
+	if err := d.writeJavaManifest(); err != nil {
+		d.log.Warn("failed to record java files", slog.String("error", err.Error()))
+	}
+
 	return os.Remove(zipPath)
 }
 
+func (d *Downloader) getJavaDir() string {
+	return filepath.Join(d.cfg.GameDir, "java")
+}
+
+// javaFile is an entry of java manifest written after extraction, used by Verify
+type javaFile struct {
+	Path string `json:"path"`
+	SHA1 string `json:"sha1"`
+	Size int64  `json:"size"`
+}
+
+func (d *Downloader) writeJavaManifest() error {
+	javaDir := d.getJavaDir()
+
+	var files []javaFile
+	err := filepath.WalkDir(javaDir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		rel, err := filepath.Rel(javaDir, p)
+		if err != nil || rel == javaManifest {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		hash, err := FileSHA1(p)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, javaFile{Path: filepath.ToSlash(rel), SHA1: hash, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(javaDir, javaManifest), data, 0644)
+}
+
+func (d *Downloader) readJavaManifest() ([]javaFile, error) {
+	data, err := os.ReadFile(filepath.Join(d.getJavaDir(), javaManifest))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []javaFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse java manifest: %v", err)
+	}
+
+	return files, nil
+}
+
 // e.g. OpenJDK21U-jdk_x64_mac_hotspot_21.0.9_10.tar.gz
 func (d *Downloader) getJavaDownloadURL() string {
 	arch := formatJavaReleaseArch()
@@ -92,43 +173,13 @@ func formatJavaReleaseArch() string {
 	return ""
 }
 
+// java archive is the biggest download, it goes last so it doesnt hold up anything else
 func (d *Downloader) downloadJava(url, dest string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
-	}
-
-	out, err := os.Create(dest)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	buffer := make([]byte, 32*1024)
-	for {
-		n, err := resp.Body.Read(buffer)
-		if n > 0 {
-			out.Write(buffer[:n])
-		}
-
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-	}
-
-	return nil
+	return d.downloadAll(PhaseJava, []Job{{Name: "java", URL: url, Path: dest, Priority: PriorityLow}})
 }
 
 func (d *Downloader) extractJava(zipPath string) error {
-	javaDir := filepath.Join(d.cfg.GameDir, "java")
+	javaDir := d.getJavaDir()
 	os.RemoveAll(javaDir)
 	err := os.MkdirAll(javaDir, 0755)
 	if err != nil {
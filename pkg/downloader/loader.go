@@ -0,0 +1,301 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+const (
+	LoaderFabric  = "fabric"
+	LoaderQuilt   = "quilt"
+	LoaderVanilla = "vanilla"
+)
+
+// Loader is a mod loader installed as a version profile on top of vanilla
+type Loader interface {
+	// fabric, quilt...
+	Name() string
+	Version() string
+	// folder & profile name in versions dir
+	VersionName() string
+	// Install writes version profile and downloads everything it needs,
+	// vanilla client has to be installed first
+	Install(d *Downloader) error
+
+	// Libraries of the installed loader, they go on the classpath before vanilla ones
+	Libraries(d *Downloader) ([]LoaderLibrary, error)
+	MainClass(d *Downloader) (string, error)
+	// Arguments are added after vanilla arguments of the same kind
+	Arguments(d *Downloader) (*LoaderArguments, error)
+	// ClientJar is the minecraft jar to put on the classpath
+	ClientJar(d *Downloader) string
+}
+
+type LoaderArguments struct {
+	Game []interface{} `json:"game"`
+	JVM  []interface{} `json:"jvm"`
+}
+
+// LoaderProfile is the version json loaders install into versions dir
+type LoaderProfile struct {
+	ID           string          `json:"id"`
+	InheritsFrom string          `json:"inheritsFrom"`
+	MainClass    string          `json:"mainClass"`
+	Arguments    LoaderArguments `json:"arguments"`
+	Libraries    []LoaderLibrary `json:"libraries"`
+}
+
+type LoaderLibrary struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+	// fabric meta publishes these, older profiles dont have them
+	SHA1 string `json:"sha1,omitempty"`
+	Size int64  `json:"size,omitempty"`
+	// neoforge profiles carry full downloads, fabric & quilt only maven names
+	Downloads *types.LibraryDownloads `json:"downloads,omitempty"`
+}
+
+// Path is slash separated path of the library inside libraries dir
+func (l LoaderLibrary) Path() string {
+	if l.Downloads != nil && l.Downloads.Artifact.Path != "" {
+		return l.Downloads.Artifact.Path
+	}
+
+	return parseMavenCoord(l.Name).path()
+}
+
+// source of the library, mavenURL is used when it doesnt name its repository.
+// Empty url means the loader installer provides it (neoforge)
+func (l LoaderLibrary) source(mavenURL string) (url, sha1 string, size int64) {
+	if l.Downloads != nil {
+		a := l.Downloads.Artifact
+		return a.URL, a.SHA1, int64(a.Size)
+	}
+
+	repository := l.URL
+	if repository == "" {
+		repository = mavenURL
+	}
+
+	return mavenToURL(repository, l.Name), l.SHA1, l.Size
+}
+
+// loaders downloading libraries from a maven repository by default
+type mavenLoader interface {
+	MavenURL() string
+}
+
+// LibraryKey identifies library regardless of its version,
+// loader libraries replace vanilla ones with the same key
+func LibraryKey(name string) string {
+	c := parseMavenCoord(name)
+	return c.group + ":" + c.artifact + ":" + c.classifier
+}
+
+// ReadLoaderProfile reads version json the loader installed into versions dir
+func (d *Downloader) ReadLoaderProfile(versionName string) (*LoaderProfile, error) {
+	data, err := os.ReadFile(filepath.Join(d.cfg.GameDir, "versions", versionName, versionName+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var profile LoaderProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse %s profile: %v", versionName, err)
+	}
+
+	return &profile, nil
+}
+
+// installedProfile implements launch side of Loader from the version profile the loader installed,
+// fabric, quilt & neoforge embed it
+type installedProfile struct {
+	versionName string
+}
+
+// folder & profile name in versions dir
+func (p installedProfile) VersionName() string {
+	return p.versionName
+}
+
+func (p installedProfile) Libraries(d *Downloader) ([]LoaderLibrary, error) {
+	profile, err := d.ReadLoaderProfile(p.versionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile.Libraries, nil
+}
+
+func (p installedProfile) MainClass(d *Downloader) (string, error) {
+	profile, err := d.ReadLoaderProfile(p.versionName)
+	if err != nil {
+		return "", err
+	}
+	if profile.MainClass == "" {
+		return "", fmt.Errorf("%s profile has no main class", p.versionName)
+	}
+
+	return profile.MainClass, nil
+}
+
+func (p installedProfile) Arguments(d *Downloader) (*LoaderArguments, error) {
+	profile, err := d.ReadLoaderProfile(p.versionName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoaderArguments{
+		Game: stripSpaces(profile.Arguments.Game),
+		JVM:  stripSpaces(profile.Arguments.JVM),
+	}, nil
+}
+
+// ClientJar is the vanilla client, loaders patch it at runtime
+func (p installedProfile) ClientJar(d *Downloader) string {
+	return d.getClientPath()
+}
+
+// loader profiles pad some arguments with spaces (e.g. fabric's -DFabricMcEmu),
+// strip them before substituting since paths may contain spaces
+func stripSpaces(arguments []interface{}) []interface{} {
+	stripped := make([]interface{}, 0, len(arguments))
+	for _, arg := range arguments {
+		if s, ok := arg.(string); ok {
+			arg = strings.ReplaceAll(s, " ", "")
+		}
+		stripped = append(stripped, arg)
+	}
+
+	return stripped
+}
+
+// LoaderFor picks loader configured for the instance, nil for vanilla ones
+func LoaderFor(cfg *config.Config) (Loader, error) {
+	switch cfg.Versions.Loader {
+	case LoaderVanilla:
+		return nil, nil
+	case "", LoaderFabric:
+		return NewFabricLoader(cfg.Versions.Minecraft, cfg.Versions.FabricLoader), nil
+	case LoaderQuilt:
+		return NewQuiltLoader(cfg.Versions.Minecraft, cfg.Versions.QuiltLoader), nil
+	case LoaderNeoForge:
+		return NewNeoForgeLoader(cfg.Versions.Minecraft, cfg.Versions.NeoForge), nil
+	}
+
+	return nil, fmt.Errorf("unsupported mod loader: %s", cfg.Versions.Loader)
+}
+
+func (d *Downloader) InstallLoader(loader Loader) error {
+	d.log.Info("installing loader", slog.String("loader", loader.Name()),
+		slog.String("loader_version", loader.Version()), slog.String("mc_version", d.cfg.Versions.Minecraft))
+
+	if err := loader.Install(d); err != nil {
+		return err
+	}
+
+	d.log.Info("installed loader", slog.String("version", loader.VersionName()))
+	return nil
+}
+
+// installMetaProfile installs loaders publishing ready to use profiles
+// through a meta api, fabric & quilt share the format
+func (d *Downloader) installMetaProfile(versionName, profileURL, mavenURL string) error {
+	versionDir := filepath.Join(d.cfg.GameDir, "versions", versionName)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return err
+	}
+
+	var profile LoaderProfile
+	if err := d.fetchMetadataJSON(profileURL, &profile); err != nil {
+		return err
+	}
+
+	profileData, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	profilePath := filepath.Join(versionDir, versionName+".json")
+	if err := os.WriteFile(profilePath, profileData, 0644); err != nil {
+		return err
+	}
+
+	return d.downloadLoaderLibraries(mavenURL, profile.Libraries)
+}
+
+func (d *Downloader) downloadLoaderLibraries(mavenURL string, libraries []LoaderLibrary) error {
+	jobs := make([]Job, 0, len(libraries))
+	for _, library := range libraries {
+		url, sha1, size := library.source(mavenURL)
+		jobs = append(jobs, Job{
+			Name: library.Name, URL: url, SHA1: sha1, Size: size,
+			Path: filepath.Join(d.getLibrariesPath(), filepath.FromSlash(library.Path())), Priority: PriorityHigh,
+		})
+	}
+
+	d.log.Info("downloading loader libraries", slog.Int("total", len(jobs)))
+	if err := d.downloadAll(PhaseLoader, jobs); err != nil {
+		return fmt.Errorf("failed to download loader libraries: %v", err)
+	}
+
+	return nil
+}
+
+// group:artifact:version[:classifier][@extension]
+type mavenCoord struct {
+	group      string
+	artifact   string
+	version    string
+	classifier string
+	extension  string
+}
+
+func parseMavenCoord(name string) mavenCoord {
+	name, ext, ok := strings.Cut(name, "@")
+	if !ok {
+		ext = "jar"
+	}
+
+	coord := mavenCoord{extension: ext}
+	parts := strings.Split(name, ":")
+	if len(parts) >= 3 {
+		coord.group = strings.ReplaceAll(parts[0], ".", "/")
+		coord.artifact = parts[1]
+		coord.version = parts[2]
+	}
+	if len(parts) >= 4 {
+		coord.classifier = parts[3]
+	}
+
+	return coord
+}
+
+// slash separated path inside maven repository
+func (c mavenCoord) path() string {
+	file := c.artifact + "-" + c.version
+	if c.classifier != "" {
+		file += "-" + c.classifier
+	}
+
+	return c.group + "/" + c.artifact + "/" + c.version + "/" + file + "." + c.extension
+}
+
+func mavenToPath(name string) string {
+	return filepath.FromSlash(parseMavenCoord(name).path())
+}
+
+func mavenToURL(repository, name string) string {
+	if !strings.HasSuffix(repository, "/") {
+		repository += "/"
+	}
+
+	return repository + parseMavenCoord(name).path()
+}
@@ -0,0 +1,434 @@
+package downloader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	LockfilePath      = "tblock_lock.json"
+	DisabledModSuffix = ".disabled"
+)
+
+// Lockfile tracks every mod & resourcepack the launcher has put into the game dir,
+// so updates only touch files we own and leave whatever the player added alone
+type Lockfile struct {
+	Files []LockedFile `json:"files"`
+	// mods turned off by the player, stored as <path> but living on disk as <path>.disabled
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+type LockedFile struct {
+	// relative to game dir, always slash separated
+	Path   string `json:"path"`
+	SHA1   string `json:"sha1"`
+	Source string `json:"source"`
+}
+
+type ConflictReason int
+
+const (
+	// file we want to write already exists but was not installed by the launcher
+	ConflictUntracked ConflictReason = iota
+	// file was installed by the launcher but changed since
+	ConflictModified
+	// file found while adopting an install made before the lockfile existed
+	// that the launcher doesnt want anymore, may duplicate a newer mod
+	ConflictLegacy
+)
+
+type ResourceConflict struct {
+	Path   string
+	Reason ConflictReason
+}
+
+func (c ResourceConflict) String() string {
+	switch c.Reason {
+	case ConflictModified:
+		return fmt.Sprintf("%s: modified after install, kept", c.Path)
+	case ConflictLegacy:
+		return fmt.Sprintf("%s: left from an older launcher version, kept", c.Path)
+	default:
+		return fmt.Sprintf("%s: not installed by launcher, kept", c.Path)
+	}
+}
+
+func (l *Lockfile) find(p string) (int, *LockedFile) {
+	for i := range l.Files {
+		if l.Files[i].Path == p {
+			return i, &l.Files[i]
+		}
+	}
+
+	return -1, nil
+}
+
+// Lookup returns entry for slash separated path relative to game dir
+func (l *Lockfile) Lookup(p string) (LockedFile, bool) {
+	_, file := l.find(p)
+	if file == nil {
+		return LockedFile{}, false
+	}
+
+	return *file, true
+}
+
+func (l *Lockfile) put(file LockedFile) {
+	if i, _ := l.find(file.Path); i >= 0 {
+		l.Files[i] = file
+		return
+	}
+
+	l.Files = append(l.Files, file)
+}
+
+func (l *Lockfile) remove(p string) {
+	if i, _ := l.find(p); i >= 0 {
+		l.Files = append(l.Files[:i], l.Files[i+1:]...)
+	}
+}
+
+func (l *Lockfile) IsDisabled(p string) bool {
+	for _, disabled := range l.Disabled {
+		if disabled == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *Lockfile) setDisabled(p string, disabled bool) {
+	if disabled == l.IsDisabled(p) {
+		return
+	}
+
+	if disabled {
+		l.Disabled = append(l.Disabled, p)
+		return
+	}
+
+	for i := range l.Disabled {
+		if l.Disabled[i] == p {
+			l.Disabled = append(l.Disabled[:i], l.Disabled[i+1:]...)
+			return
+		}
+	}
+}
+
+// diskPath is where file at p actually lives, taking disabled mods into account
+func (l *Lockfile) diskPath(gameDir, p string) string {
+	if l.IsDisabled(p) {
+		p += DisabledModSuffix
+	}
+
+	return filepath.Join(gameDir, filepath.FromSlash(p))
+}
+
+// returns empty lockfile if there is none yet
+func (d *Downloader) ReadLockfile() (*Lockfile, error) {
+	lock, _, err := d.readLockfile()
+	return lock, err
+}
+
+// readLockfile also reports whether the lockfile exists on disk
+func (d *Downloader) readLockfile() (*Lockfile, bool, error) {
+	data, err := os.ReadFile(filepath.Join(d.cfg.GameDir, LockfilePath))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Lockfile{}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var lock Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, true, fmt.Errorf("failed to parse lockfile: %v", err)
+	}
+
+	return &lock, true, nil
+}
+
+func (d *Downloader) writeLockfile(lock *Lockfile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	lockPath := filepath.Join(d.cfg.GameDir, LockfilePath)
+	tmpPath := lockPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, lockPath)
+}
+
+// owned reports whether the file at p is still exactly what the launcher installed
+func (d *Downloader) owned(lock *Lockfile, p string) (bool, error) {
+	_, locked := lock.find(p)
+	if locked == nil {
+		return false, nil
+	}
+
+	hash, err := FileSHA1(lock.diskPath(d.cfg.GameDir, p))
+	if err != nil {
+		return false, err
+	}
+
+	return hash == locked.SHA1, nil
+}
+
+// SetDisabled persists whether mod at p (e.g. mods/sodium.jar) is turned off,
+// renaming the file is up to the caller
+func (d *Downloader) SetDisabled(p string, disabled bool) error {
+	lock, err := d.ReadLockfile()
+	if err != nil {
+		return err
+	}
+
+	lock.setDisabled(p, disabled)
+	return d.writeLockfile(lock)
+}
+
+// modrinth cdn urls look like https://cdn.modrinth.com/data/<project>/versions/<version>/<file>,
+// so newer versions of the same mod can inherit disabled state of the old one
+func sourceProject(source string) string {
+	_, rest, ok := strings.Cut(source, "cdn.modrinth.com/data/")
+	if !ok {
+		return source
+	}
+
+	project, _, _ := strings.Cut(rest, "/")
+	return project
+}
+
+func resourcePath(r ResouceData) string {
+	dir := "mods"
+	if r.Type == ResourcePack {
+		dir = "resourcepacks"
+	}
+
+	return path.Join(dir, path.Base(r.URL))
+}
+
+// SyncResources brings mods & resourcepacks in line with resources.
+// Only files recorded in the lockfile are removed or replaced, anything else
+// the player put there is kept and reported back as a conflict
+func (d *Downloader) SyncResources(resources []ResouceData) (conflicts []ResourceConflict, err error) {
+	lock, exists, err := d.readLockfile()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]ResouceData, len(resources))
+	for _, r := range resources {
+		wanted[resourcePath(r)] = r
+	}
+
+	// installs made before the lockfile existed, adopt files with matching names.
+	// Done before anything is written, so a failure here leaves it a legacy install
+	if !exists {
+		conflicts, err = d.adoptLegacy(lock, resources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// whatever happened, files already downloaded must stay owned by the launcher
+	defer func() {
+		if writeErr := d.writeLockfile(lock); writeErr != nil && err == nil {
+			err = fmt.Errorf("failed to write lockfile: %v", writeErr)
+		}
+
+		for _, c := range conflicts {
+			d.log.Warn("resource conflict", slog.String("conflict", c.String()))
+		}
+	}()
+
+	// projects the player turned off, replacements stay off too
+	disabledProjects := map[string]bool{}
+
+	// drop files we installed before but dont need anymore
+	for _, locked := range append([]LockedFile(nil), lock.Files...) {
+		if r, ok := wanted[locked.Path]; ok && r.URL == locked.Source {
+			continue
+		}
+
+		if lock.IsDisabled(locked.Path) {
+			disabledProjects[sourceProject(locked.Source)] = true
+		}
+
+		isOwned, err := d.owned(lock, locked.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			lock.remove(locked.Path)
+			lock.setDisabled(locked.Path, false)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !isOwned {
+			conflicts = append(conflicts, ResourceConflict{Path: locked.Path, Reason: ConflictModified})
+			lock.remove(locked.Path)
+			continue
+		}
+
+		d.log.Info("removing outdated resource", slog.String("path", locked.Path))
+		if err := os.Remove(lock.diskPath(d.cfg.GameDir, locked.Path)); err != nil {
+			return nil, err
+		}
+		lock.remove(locked.Path)
+		lock.setDisabled(locked.Path, false)
+	}
+
+	var (
+		jobs  []Job
+		paths []string
+	)
+	for _, r := range resources {
+		p := resourcePath(r)
+		if r.Type == Mod && disabledProjects[sourceProject(r.URL)] {
+			lock.setDisabled(p, true)
+		}
+
+		fullPath := lock.diskPath(d.cfg.GameDir, p)
+
+		_, locked := lock.find(p)
+		if _, err := os.Stat(fullPath); err == nil {
+			if locked == nil {
+				conflicts = append(conflicts, ResourceConflict{Path: p, Reason: ConflictUntracked})
+				continue
+			}
+
+			isOwned, err := d.owned(lock, p)
+			if err != nil {
+				return nil, err
+			}
+			if !isOwned {
+				conflicts = append(conflicts, ResourceConflict{Path: p, Reason: ConflictModified})
+				continue
+			}
+
+			// already installed
+			continue
+		}
+
+		jobs = append(jobs, Job{Name: path.Base(p), URL: r.URL, Path: fullPath, Priority: PriorityNormal})
+		paths = append(paths, p)
+	}
+
+	downloadErr := d.downloadAll(PhaseMods, jobs)
+
+	// files that made it are recorded even if others failed
+	for i, job := range jobs {
+		hash, err := FileSHA1(job.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		lock.put(LockedFile{Path: paths[i], SHA1: hash, Source: job.URL})
+	}
+
+	// conflicts found so far still matter to the caller
+	if downloadErr != nil {
+		return conflicts, downloadErr
+	}
+
+	return conflicts, nil
+}
+
+// adoptLegacy records wanted files that are already there as owned. Files older launchers
+// may have installed that nothing wants now cant be told apart from files the player added,
+// so they are only reported
+func (d *Downloader) adoptLegacy(lock *Lockfile, resources []ResouceData) ([]ResourceConflict, error) {
+	wanted := make(map[string]ResouceData, len(resources))
+	for _, r := range resources {
+		p := resourcePath(r)
+		wanted[p] = r
+
+		fullPath := filepath.Join(d.cfg.GameDir, filepath.FromSlash(p))
+		if _, err := os.Stat(fullPath); err != nil {
+			continue
+		}
+
+		hash, err := FileSHA1(fullPath)
+		if err != nil {
+			return nil, err
+		}
+
+		lock.put(LockedFile{Path: p, SHA1: hash, Source: r.URL})
+	}
+
+	var conflicts []ResourceConflict
+	for _, dir := range []string{"mods", "resourcepacks"} {
+		entries, err := os.ReadDir(filepath.Join(d.cfg.GameDir, dir))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			p := path.Join(dir, entry.Name())
+			if entry.IsDir() || wanted[p].URL != "" || wanted[strings.TrimSuffix(p, DisabledModSuffix)].URL != "" {
+				continue
+			}
+
+			conflicts = append(conflicts, ResourceConflict{Path: p, Reason: ConflictLegacy})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// Track records files installed outside of SyncResources (e.g. from a modpack)
+// as owned by the launcher
+func (d *Downloader) Track(files []LockedFile) error {
+	lock, err := d.ReadLockfile()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		lock.put(file)
+	}
+
+	return d.writeLockfile(lock)
+}
+
+// ReplaceLocked points lockfile entry at oldPath to file, so resources changed
+// outside of SyncResources (e.g. mod updates) are still owned by the launcher.
+// Untracked paths are left alone
+func (d *Downloader) ReplaceLocked(oldPath string, file LockedFile) error {
+	lock, err := d.ReadLockfile()
+	if err != nil {
+		return err
+	}
+
+	i, _ := lock.find(oldPath)
+	if i < 0 {
+		return nil
+	}
+
+	// e.g. two copies of one mod updated to the same jar, keep a single entry
+	if j, _ := lock.find(file.Path); j >= 0 && j != i {
+		lock.Files[j] = file
+		lock.remove(oldPath)
+		return d.writeLockfile(lock)
+	}
+
+	lock.Files[i] = file
+	return d.writeLockfile(lock)
+}
@@ -0,0 +1,261 @@
+package downloader
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+// serves every path as its own content, /missing/ ones fail
+func resourceServer(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Base(filepath.Dir(r.URL.Path)) == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func writeFile(t *testing.T, gameDir, p, content string) {
+	t.Helper()
+
+	full := filepath.Join(gameDir, filepath.FromSlash(p))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readLock(t *testing.T, d *Downloader) *Lockfile {
+	t.Helper()
+
+	lock, err := d.ReadLockfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return lock
+}
+
+func sha1Of(t *testing.T, gameDir, p string) string {
+	t.Helper()
+
+	hash, err := FileSHA1(filepath.Join(gameDir, filepath.FromSlash(p)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hash
+}
+
+func TestSyncResources(t *testing.T) {
+	base := resourceServer(t)
+	mod := func(name string) ResouceData { return ResouceData{Type: Mod, URL: base + "/v1/" + name} }
+
+	tests := []struct {
+		name string
+		// files on disk before sync, path -> content
+		files map[string]string
+		// lockfile before sync, nil means there is none
+		lock      *Lockfile
+		resources []ResouceData
+		conflicts []ResourceConflict
+		// paths that must be locked after sync
+		locked []string
+		// paths that must be gone after sync
+		removed []string
+		err     bool
+	}{
+		{
+			name:      "fresh install downloads and locks everything",
+			lock:      &Lockfile{},
+			resources: []ResouceData{mod("a.jar"), {Type: ResourcePack, URL: base + "/v1/pack.zip"}},
+			locked:    []string{"mods/a.jar", "resourcepacks/pack.zip"},
+		},
+		{
+			name:      "untracked file is kept",
+			files:     map[string]string{"mods/a.jar": "player's"},
+			lock:      &Lockfile{},
+			resources: []ResouceData{mod("a.jar")},
+			conflicts: []ResourceConflict{{Path: "mods/a.jar", Reason: ConflictUntracked}},
+		},
+		{
+			name:      "modified file is kept and no longer owned",
+			files:     map[string]string{"mods/old.jar": "edited"},
+			lock:      &Lockfile{Files: []LockedFile{{Path: "mods/old.jar", SHA1: "0000", Source: base + "/v0/old.jar"}}},
+			resources: []ResouceData{mod("a.jar")},
+			conflicts: []ResourceConflict{{Path: "mods/old.jar", Reason: ConflictModified}},
+			locked:    []string{"mods/a.jar"},
+		},
+		{
+			name:      "outdated owned file is removed",
+			files:     map[string]string{"mods/old.jar": "/v0/old.jar"},
+			lock:      &Lockfile{Files: []LockedFile{{Path: "mods/old.jar", SHA1: "", Source: base + "/v0/old.jar"}}},
+			resources: []ResouceData{mod("a.jar")},
+			locked:    []string{"mods/a.jar"},
+			removed:   []string{"mods/old.jar"},
+		},
+		{
+			name:      "legacy install adopts wanted files and reports the rest",
+			files:     map[string]string{"mods/a.jar": "/v1/a.jar", "mods/unknown.jar": "?"},
+			resources: []ResouceData{mod("a.jar")},
+			conflicts: []ResourceConflict{{Path: "mods/unknown.jar", Reason: ConflictLegacy}},
+			locked:    []string{"mods/a.jar"},
+		},
+		{
+			name:      "failed download keeps conflicts and downloaded files",
+			files:     map[string]string{"mods/b.jar": "player's"},
+			lock:      &Lockfile{},
+			resources: []ResouceData{mod("a.jar"), mod("b.jar"), {Type: Mod, URL: base + "/missing/c.jar"}},
+			conflicts: []ResourceConflict{{Path: "mods/b.jar", Reason: ConflictUntracked}},
+			locked:    []string{"mods/a.jar"},
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gameDir := t.TempDir()
+			for p, content := range tt.files {
+				writeFile(t, gameDir, p, content)
+			}
+
+			d := New(&config.Config{GameDir: gameDir})
+			if tt.lock != nil {
+				// owned files are locked with their real hash unless the test says otherwise
+				for i, f := range tt.lock.Files {
+					if f.SHA1 == "" {
+						tt.lock.Files[i].SHA1 = sha1Of(t, gameDir, f.Path)
+					}
+				}
+				if err := d.writeLockfile(tt.lock); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			conflicts, err := d.SyncResources(tt.resources)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if !slices.Equal(conflicts, tt.conflicts) {
+				t.Errorf("expected conflicts %v, got %v", tt.conflicts, conflicts)
+			}
+
+			lock := readLock(t, d)
+			for _, p := range tt.locked {
+				locked, ok := lock.Lookup(p)
+				if !ok {
+					t.Errorf("%s is not locked", p)
+					continue
+				}
+				if hash := sha1Of(t, gameDir, p); locked.SHA1 != hash {
+					t.Errorf("%s locked with %s, file has %s", p, locked.SHA1, hash)
+				}
+			}
+			if len(lock.Files) != len(tt.locked) {
+				t.Errorf("expected %d locked files, got %v", len(tt.locked), lock.Files)
+			}
+
+			for _, p := range tt.removed {
+				if _, err := os.Stat(filepath.Join(gameDir, filepath.FromSlash(p))); !os.IsNotExist(err) {
+					t.Errorf("%s should be removed", p)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncResourcesKeepsDisabledProjectOff(t *testing.T) {
+	base := resourceServer(t)
+	gameDir := t.TempDir()
+	d := New(&config.Config{GameDir: gameDir})
+
+	oldURL := base + "/cdn.modrinth.com/data/sodium/versions/1/sodium-1.jar"
+	newURL := base + "/cdn.modrinth.com/data/sodium/versions/2/sodium-2.jar"
+	writeFile(t, gameDir, "mods/sodium-1.jar"+DisabledModSuffix, "/old")
+	err := d.writeLockfile(&Lockfile{
+		Files:    []LockedFile{{Path: "mods/sodium-1.jar", SHA1: sha1Of(t, gameDir, "mods/sodium-1.jar"+DisabledModSuffix), Source: oldURL}},
+		Disabled: []string{"mods/sodium-1.jar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.SyncResources([]ResouceData{{Type: Mod, URL: newURL}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(gameDir, "mods", "sodium-2.jar"+DisabledModSuffix)); err != nil {
+		t.Errorf("update of a disabled mod should stay disabled: %v", err)
+	}
+	if lock := readLock(t, d); !lock.IsDisabled("mods/sodium-2.jar") || lock.IsDisabled("mods/sodium-1.jar") {
+		t.Errorf("unexpected disabled list %v", lock.Disabled)
+	}
+}
+
+func TestReplaceLocked(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []LockedFile
+		old   string
+		file  LockedFile
+		want  []LockedFile
+	}{
+		{
+			name:  "entry is replaced in place",
+			files: []LockedFile{{Path: "mods/a-1.jar", SHA1: "1"}},
+			old:   "mods/a-1.jar",
+			file:  LockedFile{Path: "mods/a-2.jar", SHA1: "2", Source: "new"},
+			want:  []LockedFile{{Path: "mods/a-2.jar", SHA1: "2", Source: "new"}},
+		},
+		{
+			name:  "untracked path is left alone",
+			files: []LockedFile{{Path: "mods/b.jar", SHA1: "b"}},
+			old:   "mods/a-1.jar",
+			file:  LockedFile{Path: "mods/a-2.jar", SHA1: "2"},
+			want:  []LockedFile{{Path: "mods/b.jar", SHA1: "b"}},
+		},
+		{
+			name:  "target already tracked takes the new hash",
+			files: []LockedFile{{Path: "mods/a-1.jar", SHA1: "1"}, {Path: "mods/a-2.jar", SHA1: "stale", Source: "old"}},
+			old:   "mods/a-1.jar",
+			file:  LockedFile{Path: "mods/a-2.jar", SHA1: "2", Source: "new"},
+			want:  []LockedFile{{Path: "mods/a-2.jar", SHA1: "2", Source: "new"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(&config.Config{GameDir: t.TempDir()})
+			if err := d.writeLockfile(&Lockfile{Files: tt.files}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := d.ReplaceLocked(tt.old, tt.file); err != nil {
+				t.Fatal(err)
+			}
+
+			got, _ := json.Marshal(readLock(t, d).Files)
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package downloader
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const metaCacheDir = "meta"
+
+// cacheDir holds metadata cache of every Downloader, empty keeps it in the game dir
+var cacheDir string
+
+// SetCacheDir moves metadata cache out of game dirs, e.g. to XDG_CACHE_HOME.
+// Must be called before any downloads start
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// metaCacheEntry is stored next to cached response to revalidate it
+type metaCacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Offline reports whether any metadata had to be served from cache
+// because the network was unreachable
+func (d *Downloader) Offline() bool {
+	return d.offline.Load()
+}
+
+func (d *Downloader) metaCachePaths(url string) (string, string) {
+	hash := sha1.Sum([]byte(url))
+	name := hex.EncodeToString(hash[:])
+	dir := filepath.Join(d.cfg.GameDir, metaCacheDir)
+	if cacheDir != "" {
+		dir = filepath.Join(cacheDir, metaCacheDir)
+	}
+
+	return filepath.Join(dir, name+".json"), filepath.Join(dir, name+".meta")
+}
+
+// fetchMetadata gets json metadata (manifests, version jsons, loader profiles),
+// revalidating cached copy with ETag/Last-Modified and falling back to it when offline
+func (d *Downloader) fetchMetadata(url string) ([]byte, error) {
+	dataPath, entryPath := d.metaCachePaths(url)
+
+	cached, cacheErr := os.ReadFile(dataPath)
+	var entry metaCacheEntry
+	if cacheErr == nil {
+		if raw, err := os.ReadFile(entryPath); err == nil {
+			json.Unmarshal(raw, &entry)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheErr == nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %v", url, err)
+		}
+
+		d.log.Warn("network unavailable, using cached metadata", slog.String("url", url), slog.String("error", err.Error()))
+		d.offline.Store(true)
+		return cached, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cacheErr == nil {
+		return cached, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// server side trouble, stale data is better than none
+		if cacheErr == nil && resp.StatusCode >= 500 {
+			d.log.Warn("metadata server error, using cached metadata", slog.String("url", url), slog.String("status", resp.Status))
+			d.offline.Store(true)
+			return cached, nil
+		}
+
+		return nil, fmt.Errorf("error status for %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.storeMetadata(dataPath, entryPath, data, metaCacheEntry{
+		URL: url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"),
+	}); err != nil {
+		d.log.Warn("failed to cache metadata", slog.String("url", url), slog.String("error", err.Error()))
+	}
+
+	return data, nil
+}
+
+func (d *Downloader) storeMetadata(dataPath, entryPath string, data []byte, entry metaCacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := dataPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, dataPath); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(entryPath, raw, 0644)
+}
+
+func (d *Downloader) fetchMetadataJSON(url string, out any) error {
+	data, err := d.fetchMetadata(url)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", url, err)
+	}
+
+	return nil
+}
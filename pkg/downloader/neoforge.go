@@ -0,0 +1,414 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+const (
+	LoaderNeoForge = "neoforge"
+	neoForgeMaven  = "https://maven.neoforged.net/releases/"
+	// keeps track of processors that already ran, so reinstalls skip them
+	processorsCache = "processors.json"
+)
+
+// NeoForgeLoader installs neoforge the same way its installer does:
+// downloads libraries, writes version json and runs installer processors
+type NeoForgeLoader struct {
+	installedProfile
+	mcVersion     string
+	loaderVersion string
+}
+
+func NewNeoForgeLoader(mcVersion, loaderVersion string) *NeoForgeLoader {
+	return &NeoForgeLoader{
+		installedProfile: installedProfile{versionName: "neoforge-" + loaderVersion},
+		mcVersion:        mcVersion,
+		loaderVersion:    loaderVersion,
+	}
+}
+
+func (n *NeoForgeLoader) Name() string {
+	return LoaderNeoForge
+}
+
+func (n *NeoForgeLoader) Version() string {
+	return n.loaderVersion
+}
+
+// ClientJar is the copy of the client named after the profile, ignoreList of neoforge's bootstrap relies on that name
+func (n *NeoForgeLoader) ClientJar(d *Downloader) string {
+	clientJar := filepath.Join(d.cfg.GameDir, "versions", n.VersionName(), n.VersionName()+".jar")
+	if _, err := os.Stat(clientJar); err != nil {
+		return d.getClientPath()
+	}
+
+	return clientJar
+}
+
+func (n *NeoForgeLoader) MavenURL() string {
+	return neoForgeMaven
+}
+
+func (n *NeoForgeLoader) installerCoord() string {
+	return fmt.Sprintf("net.neoforged:neoforge:%s:installer", n.loaderVersion)
+}
+
+type neoForgeInstallProfile struct {
+	Spec       int                     `json:"spec"`
+	Profile    string                  `json:"profile"`
+	Version    string                  `json:"version"`
+	Minecraft  string                  `json:"minecraft"`
+	JSON       string                  `json:"json"`
+	Data       map[string]neoForgeData `json:"data"`
+	Processors []neoForgeProcessor     `json:"processors"`
+	Libraries  []types.Library         `json:"libraries"`
+}
+
+type neoForgeData struct {
+	Client string `json:"client"`
+	Server string `json:"server"`
+}
+
+type neoForgeProcessor struct {
+	Sides     []string          `json:"sides,omitempty"`
+	Jar       string            `json:"jar"`
+	Classpath []string          `json:"classpath"`
+	Args      []string          `json:"args"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+}
+
+func (p *neoForgeProcessor) runsOnClient() bool {
+	if len(p.Sides) == 0 {
+		return true
+	}
+
+	for _, side := range p.Sides {
+		if side == "client" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key identifying processor run in cache, changes if the step itself changes
+func (p *neoForgeProcessor) key() string {
+	hash := sha1.Sum([]byte(p.Jar + "\x00" + strings.Join(p.Args, "\x00")))
+	return hex.EncodeToString(hash[:])
+}
+
+func (n *NeoForgeLoader) Install(d *Downloader) error {
+	installerPath := filepath.Join(d.getLibrariesPath(), mavenToPath(n.installerCoord()))
+	installerJob := Job{Name: n.installerCoord(), URL: mavenToURL(neoForgeMaven, n.installerCoord()), Path: installerPath, Priority: PriorityLow}
+	if err := d.downloadAll(PhaseLoader, []Job{installerJob}); err != nil {
+		return fmt.Errorf("failed to download neoforge installer: %v", err)
+	}
+
+	installer, err := zip.OpenReader(installerPath)
+	if err != nil {
+		return err
+	}
+	defer installer.Close()
+
+	var profile neoForgeInstallProfile
+	if err := readZipJSON(&installer.Reader, "install_profile.json", &profile); err != nil {
+		return err
+	}
+
+	versionData, err := readZipEntry(&installer.Reader, strings.TrimPrefix(profile.JSON, "/"))
+	if err != nil {
+		return fmt.Errorf("failed to read version json: %v", err)
+	}
+
+	var version struct {
+		Libraries []types.Library `json:"libraries"`
+	}
+	if err := json.Unmarshal(versionData, &version); err != nil {
+		return fmt.Errorf("failed to parse version json: %v", err)
+	}
+
+	versionDir := filepath.Join(d.cfg.GameDir, "versions", n.VersionName())
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return err
+	}
+
+	// profile inheritsFrom vanilla version, launcher resolves the rest
+	if err := os.WriteFile(filepath.Join(versionDir, n.VersionName()+".json"), versionData, 0644); err != nil {
+		return err
+	}
+
+	var jobs []Job
+	// processor outputs (e.g. the patched client) have no url and are not bundled either
+	produced := map[string]string{}
+	for _, library := range append(profile.Libraries, version.Libraries...) {
+		artifact := library.Downloads.Artifact
+		if artifact.Path == "" {
+			artifact.Path = parseMavenCoord(library.Name).path()
+		}
+
+		libraryPath := filepath.Join(d.getLibrariesPath(), filepath.FromSlash(artifact.Path))
+		if artifact.URL != "" {
+			jobs = append(jobs, Job{
+				Name: library.Name, URL: artifact.URL, Path: libraryPath,
+				SHA1: artifact.SHA1, Size: int64(artifact.Size), Priority: PriorityHigh,
+			})
+			continue
+		}
+
+		if _, err := fs.Stat(&installer.Reader, "maven/"+artifact.Path); err != nil {
+			produced[library.Name] = libraryPath
+			continue
+		}
+
+		if err := d.extractInstallerLibrary(&installer.Reader, artifact.Path, libraryPath, artifact.SHA1); err != nil {
+			return fmt.Errorf("failed to extract library %s: %v", library.Name, err)
+		}
+	}
+
+	if err := d.downloadAll(PhaseLoader, jobs); err != nil {
+		return fmt.Errorf("failed to download libraries: %v", err)
+	}
+
+	if err := n.runProcessors(d, &installer.Reader, installerPath, &profile, versionDir); err != nil {
+		return err
+	}
+
+	for name, libraryPath := range produced {
+		if _, err := os.Stat(libraryPath); err != nil {
+			return fmt.Errorf("library %s is neither downloadable nor produced by installer: %v", name, err)
+		}
+	}
+
+	// launchers put the client on classpath under the profile name, neoforge ignoreList expects it there
+	if err := linkFile(d.getClientPath(), filepath.Join(versionDir, n.VersionName()+".jar")); err != nil {
+		return fmt.Errorf("failed to link client jar: %v", err)
+	}
+
+	return nil
+}
+
+// libraries without url are shipped inside the installer under maven/
+func (d *Downloader) extractInstallerLibrary(installer *zip.Reader, mavenPath, libraryPath, sha1 string) error {
+	if err := d.verifyChecksum(libraryPath, sha1); err == nil {
+		return nil
+	}
+
+	data, err := readZipEntry(installer, "maven/"+mavenPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(libraryPath), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(libraryPath, data, 0644)
+}
+
+func (n *NeoForgeLoader) runProcessors(d *Downloader, installer *zip.Reader, installerPath string, profile *neoForgeInstallProfile, versionDir string) error {
+	cachePath := filepath.Join(versionDir, processorsCache)
+	done := map[string]bool{}
+	if data, err := os.ReadFile(cachePath); err == nil {
+		json.Unmarshal(data, &done)
+	}
+
+	vars, err := n.processorVariables(d, installer, installerPath, profile, versionDir)
+	if err != nil {
+		return err
+	}
+
+	for i, processor := range profile.Processors {
+		if !processor.runsOnClient() {
+			continue
+		}
+
+		if done[processor.key()] && d.processorOutputsValid(processor, vars) == nil {
+			d.log.Info("skipping completed neoforge processor", slog.Int("index", i), slog.String("jar", processor.Jar))
+			continue
+		}
+
+		d.log.Info("running neoforge processor", slog.Int("index", i), slog.String("jar", processor.Jar))
+		if err := d.runProcessor(processor, vars); err != nil {
+			return fmt.Errorf("processor %s failed: %v", processor.Jar, err)
+		}
+
+		if err := d.processorOutputsValid(processor, vars); err != nil {
+			return fmt.Errorf("processor %s produced invalid output: %v", processor.Jar, err)
+		}
+
+		done[processor.key()] = true
+		data, err := json.Marshal(done)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(cachePath, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// values for {NAME} placeholders in processor args & outputs
+func (n *NeoForgeLoader) processorVariables(d *Downloader, installer *zip.Reader, installerPath string, profile *neoForgeInstallProfile, versionDir string) (map[string]string, error) {
+	vars := map[string]string{
+		"SIDE":              "client",
+		"MINECRAFT_JAR":     d.getClientPath(),
+		"MINECRAFT_VERSION": d.cfg.Versions.Minecraft,
+		"ROOT":              d.cfg.GameDir,
+		"INSTALLER":         installerPath,
+		"LIBRARY_DIR":       d.getLibrariesPath(),
+	}
+
+	for key, data := range profile.Data {
+		value := data.Client
+		switch {
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			vars[key] = d.libraryPath(strings.Trim(value, "[]"))
+		case strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'"):
+			vars[key] = strings.Trim(value, "'")
+		case strings.HasPrefix(value, "/"):
+			// file bundled with installer, extract it next to the profile
+			content, err := readZipEntry(installer, strings.TrimPrefix(value, "/"))
+			if err != nil {
+				return nil, err
+			}
+
+			target := filepath.Join(versionDir, "data", filepath.FromSlash(strings.TrimPrefix(value, "/")))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(target, content, 0644); err != nil {
+				return nil, err
+			}
+
+			vars[key] = target
+		default:
+			vars[key] = value
+		}
+	}
+
+	return vars, nil
+}
+
+func (d *Downloader) libraryPath(coord string) string {
+	return filepath.Join(d.getLibrariesPath(), mavenToPath(coord))
+}
+
+func (d *Downloader) resolveProcessorArg(arg string, vars map[string]string) string {
+	if strings.HasPrefix(arg, "[") && strings.HasSuffix(arg, "]") {
+		return d.libraryPath(strings.Trim(arg, "[]"))
+	}
+
+	if strings.HasPrefix(arg, "'") && strings.HasSuffix(arg, "'") {
+		return strings.Trim(arg, "'")
+	}
+
+	for key, value := range vars {
+		arg = strings.ReplaceAll(arg, "{"+key+"}", value)
+	}
+
+	return arg
+}
+
+func (d *Downloader) runProcessor(processor neoForgeProcessor, vars map[string]string) error {
+	jarPath := d.libraryPath(processor.Jar)
+	mainClass, err := jarMainClass(jarPath)
+	if err != nil {
+		return err
+	}
+
+	classpath := []string{jarPath}
+	for _, coord := range processor.Classpath {
+		classpath = append(classpath, d.libraryPath(coord))
+	}
+
+	args := []string{"-cp", strings.Join(classpath, string(os.PathListSeparator)), mainClass}
+	for _, arg := range processor.Args {
+		args = append(args, d.resolveProcessorArg(arg, vars))
+	}
+
+	cmd := exec.Command(JavaExecutable(d.cfg.JavaPath), args...)
+	cmd.Dir = d.cfg.GameDir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		d.log.Error("neoforge processor output", slog.String("output", string(output)))
+		return err
+	}
+
+	return nil
+}
+
+// outputs map file paths to expected sha1, both may be placeholders
+func (d *Downloader) processorOutputsValid(processor neoForgeProcessor, vars map[string]string) error {
+	for file, hash := range processor.Outputs {
+		if err := d.verifyChecksum(d.resolveProcessorArg(file, vars), d.resolveProcessorArg(hash, vars)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func jarMainClass(jarPath string) (string, error) {
+	r, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	f, err := r.Open("META-INF/MANIFEST.MF")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if mainClass, ok := strings.CutPrefix(scanner.Text(), "Main-Class:"); ok {
+			return strings.TrimSpace(mainClass), nil
+		}
+	}
+
+	return "", errors.New("jar has no main class")
+}
+
+func readZipEntry(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+func readZipJSON(r *zip.Reader, name string, out any) error {
+	data, err := readZipEntry(r, name)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+
+	return nil
+}
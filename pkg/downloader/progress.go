@@ -0,0 +1,157 @@
+package downloader
+
+import (
+	"sync"
+	"time"
+)
+
+type Phase string
+
+const (
+	PhaseClient Phase = "client"
+	// client, libraries & assets together
+	PhaseGame      Phase = "game"
+	PhaseLibraries Phase = "libraries"
+	PhaseAssets    Phase = "assets"
+	PhaseJava      Phase = "java"
+	PhaseLoader    Phase = "loader"
+	PhaseMods      Phase = "mods"
+	PhaseModpack   Phase = "modpack"
+	PhaseVerify    Phase = "verify"
+	PhaseRepair    Phase = "repair"
+)
+
+// how often events are sent while a phase is running
+const progressInterval = 100 * time.Millisecond
+
+// ProgressEvent describes state of the phase currently running
+type ProgressEvent struct {
+	Phase Phase
+	// last file finished, may be empty
+	Item       string
+	BytesDone  int64
+	BytesTotal int64
+	FilesDone  int
+	FilesTotal int
+	// bytes per second, 0 if phase doesnt download anything
+	Throughput float64
+	// 0 if unknown
+	ETA  time.Duration
+	Done bool
+}
+
+// Fraction is progress from 0 to 1, by bytes if they are known and by files otherwise
+func (e ProgressEvent) Fraction() float64 {
+	if e.BytesTotal > 0 {
+		return min(float64(e.BytesDone)/float64(e.BytesTotal), 1)
+	}
+
+	if e.FilesTotal > 0 {
+		return float64(e.FilesDone) / float64(e.FilesTotal)
+	}
+
+	return 0
+}
+
+type ProgressFunc func(ProgressEvent)
+
+// WithProgress subscribes fn to progress of everything this downloader does.
+// fn may be called from several goroutines
+func (d *Downloader) WithProgress(fn ProgressFunc) *Downloader {
+	d.onProgress = fn
+	return d
+}
+
+// progressTracker collects progress of a single phase and throttles events
+type progressTracker struct {
+	mu       sync.Mutex
+	emit     ProgressFunc
+	event    ProgressEvent
+	start    time.Time
+	lastEmit time.Time
+}
+
+// track starts a phase, bytesTotal may grow later as sizes become known
+func (d *Downloader) track(phase Phase, filesTotal int, bytesTotal int64) *progressTracker {
+	t := &progressTracker{
+		emit:  d.onProgress,
+		event: ProgressEvent{Phase: phase, FilesTotal: filesTotal, BytesTotal: bytesTotal},
+		start: time.Now(),
+	}
+	t.send(true)
+
+	return t
+}
+
+func (t *progressTracker) update(force bool, fn func(e *ProgressEvent)) {
+	if t == nil || t.emit == nil {
+		return
+	}
+
+	t.mu.Lock()
+	fn(&t.event)
+	t.mu.Unlock()
+
+	t.send(force)
+}
+
+func (t *progressTracker) addBytes(n int64) {
+	t.update(false, func(e *ProgressEvent) { e.BytesDone += n })
+}
+
+func (t *progressTracker) addTotal(n int64) {
+	t.update(false, func(e *ProgressEvent) { e.BytesTotal += n })
+}
+
+// skip marks file as done without downloading it, its size doesnt count towards throughput
+func (t *progressTracker) skip(item string, size int64) {
+	t.update(false, func(e *ProgressEvent) {
+		e.BytesTotal -= size
+		e.FilesDone++
+		e.Item = item
+	})
+}
+
+func (t *progressTracker) fileDone(item string) {
+	t.update(false, func(e *ProgressEvent) {
+		e.FilesDone++
+		e.Item = item
+	})
+}
+
+func (t *progressTracker) finish() {
+	t.update(true, func(e *ProgressEvent) { e.Done = true })
+}
+
+func (t *progressTracker) send(force bool) {
+	if t == nil || t.emit == nil {
+		return
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	if !force && now.Sub(t.lastEmit) < progressInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.lastEmit = now
+
+	event := t.event
+	elapsed := now.Sub(t.start).Seconds()
+	t.mu.Unlock()
+
+	if elapsed > 0 {
+		event.Throughput = float64(event.BytesDone) / elapsed
+	}
+
+	switch {
+	case event.Done:
+	case event.BytesTotal > 0 && event.Throughput > 0:
+		event.ETA = time.Duration(float64(event.BytesTotal-event.BytesDone) / event.Throughput * float64(time.Second))
+	case event.FilesTotal > 0 && event.FilesDone > 0:
+		perFile := elapsed / float64(event.FilesDone)
+		event.ETA = time.Duration(perFile * float64(event.FilesTotal-event.FilesDone) * float64(time.Second))
+	}
+
+	t.emit(event)
+}
@@ -0,0 +1,40 @@
+package downloader
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+// NewHTTPClient returns client going through proxy from cfg, or default client without one
+func NewHTTPClient(cfg *config.Config) (*http.Client, error) {
+	proxyURL, err := cfg.Proxy.URL()
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL == nil {
+		return http.DefaultClient, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func defaultHTTPClient(cfg *config.Config) *http.Client {
+	client, err := NewHTTPClient(cfg)
+	if err != nil {
+		slog.Warn("ignoring invalid proxy settings", slog.String("error", err.Error()))
+		return http.DefaultClient
+	}
+
+	return client
+}
+
+// HTTPClient is the client downloader uses, share it with api clients so they go through the same proxy
+func (d *Downloader) HTTPClient() *http.Client {
+	return d.client
+}
@@ -0,0 +1,37 @@
+package downloader
+
+import "fmt"
+
+type QuiltLoader struct {
+	installedProfile
+	mcVersion     string
+	loaderVersion string
+}
+
+func NewQuiltLoader(mcVersion, loaderVersion string) *QuiltLoader {
+	return &QuiltLoader{
+		installedProfile: installedProfile{versionName: fmt.Sprintf("quilt-loader-%s-%s", loaderVersion, mcVersion)},
+		mcVersion:        mcVersion,
+		loaderVersion:    loaderVersion,
+	}
+}
+
+func (q *QuiltLoader) Name() string {
+	return LoaderQuilt
+}
+
+func (q *QuiltLoader) Version() string {
+	return q.loaderVersion
+}
+
+func (q *QuiltLoader) ProfileURL() string {
+	return fmt.Sprintf("https://meta.quiltmc.org/v3/versions/loader/%s/%s/profile/json", q.mcVersion, q.loaderVersion)
+}
+
+func (q *QuiltLoader) MavenURL() string {
+	return "https://maven.quiltmc.org/repository/release/"
+}
+
+func (q *QuiltLoader) Install(d *Downloader) error {
+	return d.installMetaProfile(q.VersionName(), q.ProfileURL(), q.MavenURL())
+}
@@ -0,0 +1,81 @@
+package downloader
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket shared by all downloads, so the limit
+// applies to total bandwidth no matter how many workers are running
+type RateLimiter struct {
+	mu sync.Mutex
+	// bytes per second, 0 means unlimited
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+// how long a waiting download sleeps at most before rechecking, so limit changes apply quickly
+const rateLimitTick = 100 * time.Millisecond
+
+var defaultRateLimiter = NewRateLimiter(0)
+
+func NewRateLimiter(bytesPerSec int64) *RateLimiter {
+	return &RateLimiter{rate: bytesPerSec, last: time.Now()}
+}
+
+// SetLimit changes the limit, downloads already running pick it up right away
+func (r *RateLimiter) SetLimit(bytesPerSec int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rate = max(bytesPerSec, 0)
+	r.tokens = 0
+	r.last = time.Now()
+}
+
+func (r *RateLimiter) Limit() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rate
+}
+
+// wait blocks until n bytes may be read. Bytes are taken up front and
+// the bucket may go into debt, next readers wait until it is paid off
+func (r *RateLimiter) wait(n int) {
+	for {
+		r.mu.Lock()
+		if r.rate <= 0 {
+			r.mu.Unlock()
+			return
+		}
+
+		now := time.Now()
+		// at most a second worth of burst
+		r.tokens = min(r.tokens+now.Sub(r.last).Seconds()*float64(r.rate), float64(r.rate))
+		r.last = now
+
+		if r.tokens >= 0 {
+			r.tokens -= float64(n)
+			r.mu.Unlock()
+			return
+		}
+
+		sleep := time.Duration(-r.tokens / float64(r.rate) * float64(time.Second))
+		r.mu.Unlock()
+
+		time.Sleep(min(sleep, rateLimitTick))
+	}
+}
+
+// SetBandwidthLimit limits total download speed of every Downloader
+// using the shared limiter. 0 removes the limit
+func SetBandwidthLimit(bytesPerSec int64) {
+	defaultRateLimiter.SetLimit(bytesPerSec)
+}
+
+func (d *Downloader) WithRateLimiter(limiter *RateLimiter) *Downloader {
+	d.limiter = limiter
+	return d
+}
@@ -0,0 +1,31 @@
+package downloader
+
+import (
+	"os"
+	"syscall"
+)
+
+// FICLONE ioctl, supported by btrfs, xfs and a few others
+const ficlone = 0x40049409
+
+func reflink(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, out.Fd(), ficlone, in.Fd())
+	out.Close()
+	if errno != 0 {
+		os.Remove(dst)
+		return errno
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package downloader
+
+import "errors"
+
+func reflink(src, dst string) error {
+	return errors.New("reflink is not supported on this platform")
+}
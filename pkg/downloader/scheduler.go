@@ -0,0 +1,190 @@
+package downloader
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+const (
+	MaxConcurrentDownloads = 16
+	MaxDownloadsPerHost    = 8
+)
+
+type Priority int
+
+const (
+	// metadata & libraries, small and needed before anything else
+	PriorityHigh Priority = iota
+	PriorityNormal
+	// big archives like java or installers
+	PriorityLow
+)
+
+// Job is a single file download submitted to the scheduler
+type Job struct {
+	// shown in errors & logs, defaults to path
+	Name     string
+	URL      string
+	Path     string
+	SHA1     string
+	Size     int64
+	Priority Priority
+}
+
+// Scheduler limits how many downloads run at once, globally and per host.
+// Free slots go to waiting downloads by priority, then smallest first,
+// so a bunch of tiny libraries doesnt queue behind the java archive
+type Scheduler struct {
+	mu        sync.Mutex
+	limit     int
+	hostLimit int
+	active    int
+	hosts     map[string]int
+	waiting   []*waiter
+	seq       uint64
+}
+
+type waiter struct {
+	host     string
+	priority Priority
+	size     int64
+	seq      uint64
+	ready    chan struct{}
+}
+
+// shared by every Downloader unless replaced with WithScheduler
+var defaultScheduler = NewScheduler(MaxConcurrentDownloads, MaxDownloadsPerHost)
+
+func NewScheduler(limit, hostLimit int) *Scheduler {
+	return &Scheduler{limit: limit, hostLimit: hostLimit, hosts: map[string]int{}}
+}
+
+func (w *waiter) before(other *waiter) bool {
+	if w.priority != other.priority {
+		return w.priority < other.priority
+	}
+	if w.size != other.size {
+		return w.size < other.size
+	}
+
+	return w.seq < other.seq
+}
+
+// acquire blocks until download from rawURL may start, release must be called after
+func (s *Scheduler) acquire(rawURL string, priority Priority, size int64) (release func()) {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+
+	s.mu.Lock()
+	s.seq++
+	w := &waiter{host: host, priority: priority, size: size, seq: s.seq, ready: make(chan struct{})}
+	i := sort.Search(len(s.waiting), func(i int) bool { return w.before(s.waiting[i]) })
+	s.waiting = append(s.waiting, nil)
+	copy(s.waiting[i+1:], s.waiting[i:])
+	s.waiting[i] = w
+	s.dispatch()
+	s.mu.Unlock()
+
+	<-w.ready
+
+	return func() {
+		s.mu.Lock()
+		s.active--
+		s.hosts[host]--
+		s.dispatch()
+		s.mu.Unlock()
+	}
+}
+
+// hands out free slots, s.mu must be held
+func (s *Scheduler) dispatch() {
+	for i := 0; i < len(s.waiting) && s.active < s.limit; {
+		w := s.waiting[i]
+		if s.hosts[w.host] >= s.hostLimit {
+			i++
+			continue
+		}
+
+		s.active++
+		s.hosts[w.host]++
+		s.waiting = append(s.waiting[:i], s.waiting[i+1:]...)
+		close(w.ready)
+	}
+}
+
+// DownloadAll downloads jobs concurrently, reporting them as one phase.
+// A failed job doesnt stop the others, the first error is returned after all of them ran
+func (d *Downloader) DownloadAll(phase Phase, jobs []Job) error {
+	defer d.saveHashCache()
+
+	return d.downloadAll(phase, jobs)
+}
+
+// downloadAll runs jobs concurrently through the scheduler, reporting them as one phase
+func (d *Downloader) downloadAll(phase Phase, jobs []Job) error {
+	sorted := append([]Job(nil), jobs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		return sorted[i].Size < sorted[j].Size
+	})
+
+	var total int64
+	for _, job := range sorted {
+		total += job.Size
+	}
+
+	t := d.track(phase, len(sorted), total)
+	defer t.finish()
+	defer d.saveSharedCacheIndex()
+
+	queue := make(chan Job, len(sorted))
+	for _, job := range sorted {
+		if job.Name == "" {
+			job.Name = job.Path
+		}
+		queue <- job
+	}
+	close(queue)
+
+	var (
+		mu       sync.Mutex
+		failed   int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	// scheduler decides how many actually run, workers just keep it fed
+	for range min(len(sorted), MaxConcurrentDownloads) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range queue {
+				if err := d.downloadJob(job, t); err != nil {
+					d.log.Error("failed to download file", slog.String("name", job.Name), slog.String("error", err.Error()))
+
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s: %v", job.Name, err)
+					}
+					failed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed > 0 {
+		return fmt.Errorf("%d files failed to download, first error: %v", failed, firstErr)
+	}
+
+	return nil
+}
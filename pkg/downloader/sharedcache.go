@@ -0,0 +1,323 @@
+package downloader
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keeps track of when each cached file was last used, for eviction
+const sharedCacheIndex = "tblock_cache_index.bin"
+
+// sharedCache is a content addressed store of downloaded files, <dir>/<sha1[:2]>/<sha1>,
+// that several instances can link files from instead of downloading them again
+type sharedCache struct {
+	dir string
+
+	mu     sync.Mutex
+	loaded bool
+	dirty  bool
+	// sha1 -> unix time of last use
+	used map[string]int64
+}
+
+var (
+	sharedCachesMu sync.Mutex
+	sharedCaches   = map[string]*sharedCache{}
+)
+
+// one instance per dir, so all downloaders in the process share the usage index
+func openSharedCache(dir string) *sharedCache {
+	sharedCachesMu.Lock()
+	defer sharedCachesMu.Unlock()
+
+	if c, ok := sharedCaches[dir]; ok {
+		return c
+	}
+
+	c := &sharedCache{dir: dir, used: map[string]int64{}}
+	sharedCaches[dir] = c
+	return c
+}
+
+func (d *Downloader) sharedCache() *sharedCache {
+	if d.cfg.SharedCacheDir == "" {
+		return nil
+	}
+
+	return openSharedCache(d.cfg.SharedCacheDir)
+}
+
+func (c *sharedCache) path(sha1 string) string {
+	return filepath.Join(c.dir, sha1[:2], sha1)
+}
+
+// c.mu must be held
+func (c *sharedCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+
+	file, err := os.Open(filepath.Join(c.dir, sharedCacheIndex))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	gob.NewDecoder(file).Decode(&c.used)
+}
+
+func (c *sharedCache) touch(sha1 string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+	c.used[sha1] = time.Now().Unix()
+	c.dirty = true
+}
+
+// linkFromSharedCache puts cached copy of job's file in place, reports whether it did.
+// Cached file is hashed first, a corrupt one is evicted so it gets downloaded again
+func (d *Downloader) linkFromSharedCache(job Job) bool {
+	c := d.sharedCache()
+	if c == nil || len(job.SHA1) < 2 {
+		return false
+	}
+
+	cached := c.path(job.SHA1)
+	info, err := os.Stat(cached)
+	if err != nil || (job.Size > 0 && info.Size() != job.Size) {
+		return false
+	}
+
+	hash, err := FileSHA1(cached)
+	if err != nil {
+		d.log.Warn("failed to hash cached file", slog.String("path", cached), slog.String("error", err.Error()))
+		return false
+	}
+	if !strings.EqualFold(hash, job.SHA1) {
+		d.log.Warn("cached file is corrupt, evicting it", slog.String("path", cached))
+		c.evict(job.SHA1)
+		return false
+	}
+
+	if err := linkFile(cached, job.Path); err != nil {
+		d.log.Warn("failed to use cached file", slog.String("path", job.Path), slog.String("error", err.Error()))
+		return false
+	}
+
+	c.touch(job.SHA1)
+	return true
+}
+
+func (c *sharedCache) evict(sha1 string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+	os.Remove(c.path(sha1))
+	delete(c.used, sha1)
+	c.dirty = true
+}
+
+// addToSharedCache stores freshly downloaded & verified file in the cache
+func (d *Downloader) addToSharedCache(path, sha1 string) {
+	c := d.sharedCache()
+	if c == nil || len(sha1) < 2 {
+		return
+	}
+
+	cached := c.path(sha1)
+	if _, err := os.Stat(cached); err == nil {
+		c.touch(sha1)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		d.log.Warn("failed to create shared cache dir", slog.String("error", err.Error()))
+		return
+	}
+
+	if err := linkFile(path, cached); err != nil {
+		d.log.Warn("failed to add file to shared cache", slog.String("path", path), slog.String("error", err.Error()))
+		return
+	}
+
+	c.touch(sha1)
+}
+
+// TrimSharedCache evicts least recently used files over the size limit.
+// It walks the whole cache, so call it once when an install is done
+func (d *Downloader) TrimSharedCache() {
+	c := d.sharedCache()
+	if c == nil {
+		return
+	}
+
+	if err := c.trim(d.cfg.SharedCacheSize * 1024 * 1024); err != nil {
+		d.log.Warn("failed to trim shared cache", slog.String("error", err.Error()))
+	}
+}
+
+// saveSharedCacheIndex persists last use times without trimming
+func (d *Downloader) saveSharedCacheIndex() {
+	c := d.sharedCache()
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.save(); err != nil {
+		d.log.Warn("failed to save shared cache index", slog.String("error", err.Error()))
+	}
+}
+
+type cachedFile struct {
+	sha1     string
+	path     string
+	size     int64
+	lastUsed int64
+}
+
+// trim removes oldest files until cache fits into limit bytes, 0 means no limit
+func (c *sharedCache) trim(limit int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.load()
+
+	if limit > 0 {
+		var (
+			files []cachedFile
+			total int64
+		)
+
+		err := filepath.WalkDir(c.dir, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.Type().IsRegular() || filepath.Dir(p) == c.dir {
+				return err
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+
+			sha1 := entry.Name()
+			lastUsed, ok := c.used[sha1]
+			if !ok {
+				lastUsed = info.ModTime().Unix()
+			}
+
+			files = append(files, cachedFile{sha1: sha1, path: p, size: info.Size(), lastUsed: lastUsed})
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(files, func(i, j int) bool { return files[i].lastUsed < files[j].lastUsed })
+
+		for _, f := range files {
+			if total <= limit {
+				break
+			}
+
+			if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			total -= f.size
+			delete(c.used, f.sha1)
+			c.dirty = true
+		}
+	}
+
+	return c.save()
+}
+
+// c.mu must be held
+func (c *sharedCache) save() error {
+	if !c.dirty {
+		return nil
+	}
+
+	indexPath := filepath.Join(c.dir, sharedCacheIndex)
+	tmpPath := indexPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(file).Encode(c.used)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// linkFile makes dst have the same content as src: hardlink if possible,
+// reflink on filesystems supporting it and plain copy otherwise
+func linkFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	os.Remove(dst)
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	tmpPath := dst + ".tmp"
+	if err := reflink(src, tmpPath); err == nil {
+		return os.Rename(tmpPath, dst)
+	}
+
+	if err := copyFile(src, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, dst)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
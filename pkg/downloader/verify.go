@@ -0,0 +1,395 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// files hashed at once while verifying
+const concurrentChecks = 10
+
+type FileCategory string
+
+const (
+	CategoryClient     FileCategory = "client"
+	CategoryLibrary    FileCategory = "library"
+	CategoryNative     FileCategory = "native"
+	CategoryAssetIndex FileCategory = "asset index"
+	CategoryAsset      FileCategory = "asset"
+	CategoryJava       FileCategory = "java"
+	CategoryMod        FileCategory = "mod"
+	// loader profile & its libraries
+	CategoryLoader FileCategory = "loader"
+)
+
+type FileProblemKind int
+
+const (
+	FileMissing FileProblemKind = iota
+	FileSizeMismatch
+	FileCorrupt
+)
+
+// FileProblem is a single missing or broken file found by Verify
+type FileProblem struct {
+	Category FileCategory
+	Kind     FileProblemKind
+	target   verifyTarget
+}
+
+func (p FileProblem) Path() string {
+	return p.target.path
+}
+
+func (p FileProblem) String() string {
+	switch p.Kind {
+	case FileSizeMismatch:
+		return fmt.Sprintf("%s %s: wrong size", p.Category, p.target.path)
+	case FileCorrupt:
+		return fmt.Sprintf("%s %s: checksum mismatch", p.Category, p.target.path)
+	default:
+		return fmt.Sprintf("%s %s: missing", p.Category, p.target.path)
+	}
+}
+
+type VerifyReport struct {
+	Checked  int
+	Problems []FileProblem
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// file we know how to check and re-download
+type verifyTarget struct {
+	category FileCategory
+	path     string
+	url      string
+	sha1     string
+	// 0 if unknown
+	size int64
+	// present but unusable, e.g. profile that doesnt parse
+	broken bool
+}
+
+// Verify checks size & hash of every file the installation consists of:
+// client jar, libraries, natives, assets, java runtime, loader and launcher-installed mods.
+// Files unchanged since they were last hashed are trusted unless deep is set
+func (d *Downloader) Verify(deep bool) (*VerifyReport, error) {
+	targets, err := d.verifyTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make(chan verifyTarget, len(targets))
+	for _, t := range targets {
+		jobs <- t
+	}
+	close(jobs)
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = &VerifyReport{Checked: len(targets)}
+	)
+
+	t := d.track(PhaseVerify, len(targets), 0)
+
+	for range concurrentChecks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for target := range jobs {
+				problem, ok := d.checkTarget(target, deep)
+
+				if !ok {
+					mu.Lock()
+					report.Problems = append(report.Problems, problem)
+					mu.Unlock()
+				}
+				t.fileDone(target.path)
+			}
+		}()
+	}
+	wg.Wait()
+	t.finish()
+	d.saveHashCache()
+
+	d.log.Info("verified installation", slog.Int("checked", report.Checked), slog.Int("problems", len(report.Problems)))
+
+	return report, nil
+}
+
+func (d *Downloader) checkTarget(t verifyTarget, deep bool) (FileProblem, bool) {
+	problem := FileProblem{Category: t.category, target: t}
+
+	info, err := os.Stat(t.path)
+	if err != nil || info.IsDir() {
+		problem.Kind = FileMissing
+		return problem, false
+	}
+
+	if t.broken {
+		problem.Kind = FileCorrupt
+		return problem, false
+	}
+
+	if t.size > 0 && info.Size() != t.size {
+		problem.Kind = FileSizeMismatch
+		return problem, false
+	}
+
+	if t.sha1 == "" {
+		return problem, true
+	}
+
+	hashFile := d.cachedSHA1
+	if deep {
+		hashFile = FileSHA1
+	}
+
+	hash, err := hashFile(t.path)
+
+	if err != nil || hash != t.sha1 {
+		problem.Kind = FileCorrupt
+		return problem, false
+	}
+
+	// next normal verify can trust what we just hashed
+	if deep {
+		d.storeSHA1(t.path, hash)
+	}
+
+	return problem, true
+}
+
+func (d *Downloader) verifyTargets() ([]verifyTarget, error) {
+	details, err := d.ReadVersionDetails(d.cfg.Versions.Minecraft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version details: %v", err)
+	}
+
+	client := details.Downloads.Client
+	targets := []verifyTarget{{
+		category: CategoryClient, path: d.getClientPath(),
+		url: client.URL, sha1: client.SHA1, size: int64(client.Size),
+	}}
+
+	for _, library := range details.Libraries {
+		artifact := library.Downloads.Artifact
+		if artifact.URL == "" || !d.shouldDownloadLibrary(library) || filepath.Base(artifact.Path) == "asm-9.6.jar" {
+			continue
+		}
+
+		category := CategoryLibrary
+		if strings.Contains(library.Name, ":natives-") {
+			category = CategoryNative
+		}
+
+		targets = append(targets, verifyTarget{
+			category: category, path: filepath.Join(d.getLibrariesPath(), filepath.FromSlash(artifact.Path)),
+			url: artifact.URL, sha1: artifact.SHA1, size: int64(artifact.Size),
+		})
+	}
+
+	indexPath := d.assetIndexPath(details.AssetIndex.ID)
+	targets = append(targets, verifyTarget{
+		category: CategoryAssetIndex, path: indexPath,
+		url: details.AssetIndex.URL, sha1: details.AssetIndex.SHA1, size: int64(details.AssetIndex.Size),
+	})
+
+	// without index we dont know which objects should be there, repair brings it back first
+	if assetIndex, err := d.parseAssetIndex(indexPath); err == nil {
+		for _, obj := range assetIndex.Objects {
+			targets = append(targets, verifyTarget{
+				category: CategoryAsset, path: d.assetPath(obj.Hash),
+				url: assetURL(obj.Hash), sha1: obj.Hash, size: int64(obj.Size),
+			})
+		}
+	}
+
+	loaderTargets, err := d.loaderTargets()
+	if err != nil {
+		return nil, err
+	}
+	targets = append(targets, loaderTargets...)
+
+	javaTargets, err := d.javaTargets()
+	if err != nil {
+		return nil, err
+	}
+	targets = append(targets, javaTargets...)
+
+	lock, err := d.ReadLockfile()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, locked := range lock.Files {
+		targets = append(targets, verifyTarget{
+			category: CategoryMod, path: lock.diskPath(d.cfg.GameDir, locked.Path),
+			url: locked.Source, sha1: locked.SHA1,
+		})
+	}
+
+	return targets, nil
+}
+
+// loaderTargets are the loader profile and libraries it lists, resolved like gc does.
+// Without a usable profile only the profile itself is reported, repair reinstalls the loader
+func (d *Downloader) loaderTargets() ([]verifyTarget, error) {
+	loader, err := LoaderFor(d.cfg)
+	if err != nil || loader == nil {
+		return nil, err
+	}
+
+	profileTarget := verifyTarget{
+		category: CategoryLoader,
+		path:     filepath.Join(d.cfg.GameDir, "versions", loader.VersionName(), loader.VersionName()+".json"),
+	}
+
+	libraries, err := loader.Libraries(d)
+	if errors.Is(err, os.ErrNotExist) {
+		return []verifyTarget{profileTarget}, nil
+	}
+	if err != nil {
+		profileTarget.broken = true
+		return []verifyTarget{profileTarget}, nil
+	}
+
+	var mavenURL string
+	if m, ok := loader.(mavenLoader); ok {
+		mavenURL = m.MavenURL()
+	}
+
+	targets := []verifyTarget{profileTarget}
+	for _, library := range libraries {
+		url, sha1, size := library.source(mavenURL)
+		targets = append(targets, verifyTarget{
+			category: CategoryLoader, path: filepath.Join(d.getLibrariesPath(), filepath.FromSlash(library.Path())),
+			url: url, sha1: sha1, size: size,
+		})
+	}
+
+	return targets, nil
+}
+
+// java archive has no per file hashes, so we record our own after extracting.
+// installs older than that only get the executable checked
+func (d *Downloader) javaTargets() ([]verifyTarget, error) {
+	// player uses their own java
+	if d.cfg.JavaPath != d.GetJavaPath() {
+		return nil, nil
+	}
+
+	files, err := d.readJavaManifest()
+	if errors.Is(err, os.ErrNotExist) {
+		return []verifyTarget{{category: CategoryJava, path: JavaExecutable(d.cfg.JavaPath)}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	javaDir := d.getJavaDir()
+	targets := make([]verifyTarget, 0, len(files))
+	for _, f := range files {
+		targets = append(targets, verifyTarget{
+			category: CategoryJava, path: filepath.Join(javaDir, filepath.FromSlash(f.Path)),
+			sha1: f.SHA1, size: f.Size,
+		})
+	}
+
+	return targets, nil
+}
+
+// Repair re-downloads files reported by Verify. Java is reinstalled as a whole,
+// so is the loader if its profile or a library without source is broken
+func (d *Downloader) Repair(report *VerifyReport) error {
+	var (
+		failed       int
+		repairJava   bool
+		repairLoader bool
+		jobs         []Job
+	)
+
+	for _, problem := range report.Problems {
+		if problem.Category == CategoryJava {
+			repairJava = true
+			continue
+		}
+
+		// profile and libraries bundled with the installer come back with the loader
+		if problem.Category == CategoryLoader && problem.target.url == "" {
+			repairLoader = true
+			continue
+		}
+
+		t := problem.target
+		if t.url == "" {
+			d.log.Warn("cant repair file without source", slog.String("path", t.path))
+			failed++
+			continue
+		}
+
+		if err := os.Remove(t.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		d.log.Info("repairing file", slog.String("problem", problem.String()))
+		jobs = append(jobs, Job{Name: t.path, URL: t.url, Path: t.path, SHA1: t.sha1, Size: t.size, Priority: PriorityHigh})
+	}
+
+	if err := d.downloadAll(PhaseRepair, jobs); err != nil {
+		d.log.Error("failed to repair files", slog.String("error", err.Error()))
+		failed++
+	}
+
+	// asset index was broken, objects could not be checked before
+	for _, problem := range report.Problems {
+		if problem.Category != CategoryAssetIndex {
+			continue
+		}
+
+		assetIndex, err := d.parseAssetIndex(problem.target.path)
+		if err != nil {
+			return fmt.Errorf("failed to parse asset index: %v", err)
+		}
+
+		if err := d.downloadAllAssets(assetIndex); err != nil {
+			return err
+		}
+	}
+
+	if repairJava {
+		if err := d.DownloadJava(); err != nil {
+			return fmt.Errorf("failed to repair java: %v", err)
+		}
+	}
+
+	if repairLoader {
+		loader, err := LoaderFor(d.cfg)
+		if err != nil {
+			return err
+		}
+
+		if err := d.InstallLoader(loader); err != nil {
+			return fmt.Errorf("failed to repair %s: %v", loader.Name(), err)
+		}
+	}
+
+	d.saveHashCache()
+	d.TrimSharedCache()
+
+	if failed > 0 {
+		return fmt.Errorf("some files could not be repaired, see log for details")
+	}
+
+	return nil
+}
@@ -3,23 +3,27 @@ package downloader
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/havrydotdev/tblock-launcher/pkg/types"
 )
 
-func (d *Downloader) GetVersionURL() (string, error) {
-	url := "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
+const versionManifestURL = "https://piston-meta.mojang.com/mc/game/version_manifest_v2.json"
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch version manifest: %v", err)
+func (d *Downloader) GetVersionManifest() (*types.VersionManifest, error) {
+	var manifest types.VersionManifest
+	if err := d.fetchMetadataJSON(versionManifestURL, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to fetch version manifest: %v", err)
 	}
-	defer resp.Body.Close()
 
-	var manifest types.VersionManifest
-	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
-		return "", fmt.Errorf("failed to parse version manifest: %v", err)
+	return &manifest, nil
+}
+
+func (d *Downloader) GetVersionURL() (string, error) {
+	manifest, err := d.GetVersionManifest()
+	if err != nil {
+		return "", err
 	}
 
 	var versionURL string
@@ -38,14 +42,38 @@ func (d *Downloader) GetVersionURL() (string, error) {
 }
 
 func (d *Downloader) GetVersionDetails(versionURL string) (*types.VersionDetails, error) {
-	resp, err := http.Get(versionURL)
+	data, err := d.fetchMetadata(versionURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch version details: %v", err)
 	}
-	defer resp.Body.Close()
 
 	var details types.VersionDetails
-	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+	if err := json.Unmarshal(data, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse version details: %v", err)
+	}
+
+	// kept next to the client jar so launching doesnt need the network
+	versionDir := filepath.Join(d.cfg.GameDir, "versions", details.ID)
+	if err := os.MkdirAll(versionDir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(versionDir, details.ID+".json"), data, 0644); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
+// ReadVersionDetails reads version json saved by GetVersionDetails
+func (d *Downloader) ReadVersionDetails(id string) (*types.VersionDetails, error) {
+	data, err := os.ReadFile(filepath.Join(d.cfg.GameDir, "versions", id, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var details types.VersionDetails
+	if err := json.Unmarshal(data, &details); err != nil {
 		return nil, fmt.Errorf("failed to parse version details: %v", err)
 	}
 
@@ -0,0 +1,25 @@
+package launcher
+
+import (
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+type Launcher interface {
+	Launch() error
+	IsInstalled() bool
+}
+
+// New picks launcher for the loader configured in cfg
+func New(cfg *config.Config) (Launcher, error) {
+	loader, err := downloader.LoaderFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if loader == nil {
+		return NewVanillaLauncher(cfg), nil
+	}
+
+	return NewLoaderLauncher(cfg, loader), nil
+}
@@ -0,0 +1,128 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+// LoaderLauncher launches any loader installed as a profile on top of vanilla (fabric, quilt, neoforge).
+// Profile is merged with the vanilla version it inheritsFrom, like the official launcher does
+type LoaderLauncher struct {
+	cfg         *config.Config
+	loader      downloader.Loader
+	versionName string
+	vanilla     *VanillaLauncher
+}
+
+func NewLoaderLauncher(cfg *config.Config, loader downloader.Loader) *LoaderLauncher {
+	return &LoaderLauncher{
+		cfg:         cfg,
+		loader:      loader,
+		versionName: loader.VersionName(),
+		vanilla:     NewVanillaLauncher(cfg),
+	}
+}
+
+func (f *LoaderLauncher) Launch() error {
+	if !f.IsInstalled() {
+		return fmt.Errorf("%s is not installed. please install it first", f.loader.Name())
+	}
+
+	details, err := f.vanilla.d.ReadVersionDetails(f.cfg.Versions.Minecraft)
+	if err != nil {
+		return fmt.Errorf("failed to read minecraft %s profile: %v", f.cfg.Versions.Minecraft, err)
+	}
+
+	args, err := f.buildArgs(details)
+	if err != nil {
+		return fmt.Errorf("failed to read %s profile: %v", f.versionName, err)
+	}
+
+	cmd := exec.Command(downloader.JavaExecutable(f.cfg.JavaPath), args...)
+	cmd.Dir = f.cfg.GameDir
+	cmd.Stdout = os.Stdout
+
+	fmt.Printf("Launching Minecraft with %s %s...\n", f.loader.Name(), f.loader.Version())
+	return cmd.Run()
+}
+
+func (f *LoaderLauncher) IsInstalled() bool {
+	profilePath := filepath.Join(f.cfg.GameDir, "versions", f.versionName, f.versionName+".json")
+	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
+		return false
+	}
+	return f.vanilla.IsInstalled()
+}
+
+// buildArgs puts loader arguments after vanilla ones, main class comes from the loader
+func (f *LoaderLauncher) buildArgs(details *types.VersionDetails) ([]string, error) {
+	d := f.vanilla.d
+
+	classpath, err := f.classpath(details)
+	if err != nil {
+		return nil, err
+	}
+
+	mainClass, err := f.loader.MainClass(d)
+	if err != nil {
+		return nil, err
+	}
+
+	loaderArgs, err := f.loader.Arguments(d)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholders := f.vanilla.placeholders(details)
+	placeholders["classpath"] = classpath
+	placeholders["version_name"] = f.versionName
+	placeholders["library_directory"] = filepath.Join(f.cfg.GameDir, "libraries")
+	placeholders["classpath_separator"] = string(os.PathListSeparator)
+
+	args := []string{"-Xmx" + f.cfg.Memory}
+	args = append(args, strings.Fields(f.cfg.JvmArgs)...)
+	args = append(args, proxyJVMArgs(f.cfg.Proxy)...)
+	args = append(args, resolveArguments(jvmArguments(details), placeholders)...)
+	args = append(args, resolveArguments(loaderArgs.JVM, placeholders)...)
+	args = append(args, mainClass)
+	args = append(args, resolveArguments(gameArguments(details), placeholders)...)
+
+	return append(args, resolveArguments(loaderArgs.Game, placeholders)...), nil
+}
+
+// classpath has loader libraries first, vanilla ones they replace are left out
+func (f *LoaderLauncher) classpath(details *types.VersionDetails) (string, error) {
+	librariesDir := filepath.Join(f.cfg.GameDir, "libraries")
+
+	libraries, err := f.loader.Libraries(f.vanilla.d)
+	if err != nil {
+		return "", err
+	}
+
+	var classpath []string
+	provided := map[string]bool{}
+	for _, library := range libraries {
+		provided[downloader.LibraryKey(library.Name)] = true
+		classpath = append(classpath, filepath.Join(librariesDir, filepath.FromSlash(library.Path())))
+	}
+
+	for _, library := range details.Libraries {
+		artifact := library.Downloads.Artifact
+		if artifact.Path == "" || !downloader.LibraryAllowed(library) || provided[downloader.LibraryKey(library.Name)] {
+			continue
+		}
+
+		classpath = append(classpath, filepath.Join(librariesDir, filepath.FromSlash(artifact.Path)))
+	}
+
+	classpath = append(classpath, f.loader.ClientJar(f.vanilla.d))
+
+	return strings.Join(classpath, string(os.PathListSeparator)), nil
+}
@@ -0,0 +1,86 @@
+package launcher
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+// stubLoader answers launch questions without any profile on disk
+type stubLoader struct {
+	libraries []downloader.LoaderLibrary
+	arguments downloader.LoaderArguments
+}
+
+func (s *stubLoader) Name() string                           { return "stub" }
+func (s *stubLoader) Version() string                        { return "1.0.0" }
+func (s *stubLoader) VersionName() string                    { return "stub-1.0.0" }
+func (s *stubLoader) Install(d *downloader.Downloader) error { return nil }
+
+func (s *stubLoader) Libraries(d *downloader.Downloader) ([]downloader.LoaderLibrary, error) {
+	return s.libraries, nil
+}
+
+func (s *stubLoader) MainClass(d *downloader.Downloader) (string, error) {
+	return "stub.Main", nil
+}
+
+func (s *stubLoader) Arguments(d *downloader.Downloader) (*downloader.LoaderArguments, error) {
+	return &s.arguments, nil
+}
+
+func (s *stubLoader) ClientJar(d *downloader.Downloader) string {
+	return "/game/client.jar"
+}
+
+func TestLoaderBuildArgs(t *testing.T) {
+	cfg := &config.Config{GameDir: "/game", Memory: "4G", Username: "player"}
+	loader := &stubLoader{
+		libraries: []downloader.LoaderLibrary{{Name: "org.ow2.asm:asm:9.8"}},
+		arguments: downloader.LoaderArguments{
+			JVM:  []interface{}{"-Dloader.version=${version_name}"},
+			Game: []interface{}{"--loader"},
+		},
+	}
+	details := &types.VersionDetails{
+		ID: "1.21.8", AssetIndex: types.AssetIndex{ID: "26"},
+		Arguments: types.Arguments{
+			JVM:  []interface{}{"-cp", "${classpath}"},
+			Game: []interface{}{"--username", "${auth_player_name}"},
+		},
+		Libraries: []types.Library{
+			{Name: "org.ow2.asm:asm:9.6", Downloads: types.LibraryDownloads{Artifact: types.Artifact{Path: "org/ow2/asm/asm/9.6/asm-9.6.jar"}}},
+			{Name: "com.mojang:brigadier:1.3.10", Downloads: types.LibraryDownloads{Artifact: types.Artifact{Path: "com/mojang/brigadier/1.3.10/brigadier-1.3.10.jar"}}},
+		},
+	}
+
+	args, err := NewLoaderLauncher(cfg, loader).buildArgs(details)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"-Xmx4G", "-cp", "-Dloader.version=stub-1.0.0", "stub.Main", "--username", "player", "--loader"}
+	rest := args
+	for _, w := range want {
+		i := slices.Index(rest, w)
+		if i < 0 {
+			t.Fatalf("expected %q in order, got %q", w, args)
+		}
+		rest = rest[i+1:]
+	}
+
+	classpath := strings.Split(args[slices.Index(args, "-cp")+1], string(os.PathListSeparator))
+	wantClasspath := []string{
+		"/game/libraries/org/ow2/asm/asm/9.8/asm-9.8.jar",
+		"/game/libraries/com/mojang/brigadier/1.3.10/brigadier-1.3.10.jar",
+		"/game/client.jar",
+	}
+	if !slices.Equal(classpath, wantClasspath) {
+		t.Errorf("expected classpath %q, got %q", wantClasspath, classpath)
+	}
+}
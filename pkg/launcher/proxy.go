@@ -0,0 +1,44 @@
+package launcher
+
+import (
+	"strconv"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+// proxyJVMArgs makes the game use the same proxy as the launcher.
+// Java reads socks5 credentials from system properties, so they end up in the command line
+// and anyone who can list processes sees them. Http proxy credentials need an Authenticator
+// set in code, so the game connects to http proxies without them
+func proxyJVMArgs(proxy config.Proxy) []string {
+	if !proxy.Enabled() {
+		return nil
+	}
+
+	port := strconv.Itoa(proxy.Port)
+
+	if proxy.Type == config.ProxySOCKS5 {
+		args := []string{
+			"-DsocksProxyHost=" + proxy.Host,
+			"-DsocksProxyPort=" + port,
+			"-DsocksProxyVersion=5",
+		}
+
+		if proxy.Username != "" {
+			args = append(args,
+				"-Djava.net.socks.username="+proxy.Username,
+				"-Djava.net.socks.password="+proxy.Password,
+			)
+		}
+
+		return args
+	}
+
+	// http proxy handles both plain and tls traffic
+	return []string{
+		"-Dhttp.proxyHost=" + proxy.Host,
+		"-Dhttp.proxyPort=" + port,
+		"-Dhttps.proxyHost=" + proxy.Host,
+		"-Dhttps.proxyPort=" + port,
+	}
+}
@@ -0,0 +1,223 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/auth"
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+const vanillaMainClass = "net.minecraft.client.main.Main"
+
+// versions before 1.13 only list game arguments, natives & classpath are up to the launcher
+var legacyJVMArguments = []interface{}{"-Djava.library.path=${natives_directory}", "-cp", "${classpath}"}
+
+// VanillaLauncher starts minecraft without any mod loader, using arguments
+// and libraries from the version json. Handy as a baseline when mods crash
+type VanillaLauncher struct {
+	cfg *config.Config
+	d   *downloader.Downloader
+}
+
+func NewVanillaLauncher(cfg *config.Config) *VanillaLauncher {
+	return &VanillaLauncher{cfg: cfg, d: downloader.New(cfg)}
+}
+
+func (v *VanillaLauncher) Launch() error {
+	if !v.IsInstalled() {
+		return fmt.Errorf("minecraft %s is not installed. please install it first", v.cfg.Versions.Minecraft)
+	}
+
+	details, err := v.d.ReadVersionDetails(v.cfg.Versions.Minecraft)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(downloader.JavaExecutable(v.cfg.JavaPath), v.buildArgs(details)...)
+	cmd.Dir = v.cfg.GameDir
+	cmd.Stdout = os.Stdout
+
+	fmt.Printf("Launching Minecraft %s...\n", details.ID)
+	return cmd.Run()
+}
+
+func (v *VanillaLauncher) IsInstalled() bool {
+	versionDir := filepath.Join(v.cfg.GameDir, "versions", v.cfg.Versions.Minecraft)
+	for _, file := range []string{"minecraft.jar", v.cfg.Versions.Minecraft + ".json"} {
+		if _, err := os.Stat(filepath.Join(versionDir, file)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v *VanillaLauncher) buildArgs(details *types.VersionDetails) []string {
+	placeholders := v.placeholders(details)
+
+	args := []string{"-Xmx" + v.cfg.Memory}
+	args = append(args, strings.Fields(v.cfg.JvmArgs)...)
+	args = append(args, proxyJVMArgs(v.cfg.Proxy)...)
+	args = append(args, resolveArguments(jvmArguments(details), placeholders)...)
+
+	mainClass := details.MainClass
+	if mainClass == "" {
+		mainClass = vanillaMainClass
+	}
+	args = append(args, mainClass)
+
+	return append(args, resolveArguments(gameArguments(details), placeholders)...)
+}
+
+func jvmArguments(details *types.VersionDetails) []interface{} {
+	if len(details.Arguments.JVM) == 0 && details.MinecraftArguments != "" {
+		return legacyJVMArguments
+	}
+
+	return details.Arguments.JVM
+}
+
+// gameArguments falls back to minecraftArguments string of versions before 1.13
+func gameArguments(details *types.VersionDetails) []interface{} {
+	if len(details.Arguments.Game) > 0 || details.MinecraftArguments == "" {
+		return details.Arguments.Game
+	}
+
+	fields := strings.Fields(details.MinecraftArguments)
+	args := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		args = append(args, field)
+	}
+
+	return args
+}
+
+func (v *VanillaLauncher) classpath(details *types.VersionDetails) string {
+	var classpath []string
+	for _, library := range details.Libraries {
+		artifact := library.Downloads.Artifact
+		if artifact.Path == "" || !downloader.LibraryAllowed(library) {
+			continue
+		}
+
+		classpath = append(classpath, filepath.Join(v.cfg.GameDir, "libraries", filepath.FromSlash(artifact.Path)))
+	}
+
+	classpath = append(classpath, filepath.Join(v.cfg.GameDir, "versions", details.ID, "minecraft.jar"))
+
+	return strings.Join(classpath, string(os.PathListSeparator))
+}
+
+func (v *VanillaLauncher) placeholders(details *types.VersionDetails) map[string]string {
+	username, uuid := auth.NewOfflineAuth(v.cfg.Username).GetAuthData()
+
+	return map[string]string{
+		"natives_directory": filepath.Join(v.cfg.GameDir, "natives"),
+		"launcher_name":     "tblock",
+		"launcher_version":  v.cfg.Versions.Launcher,
+		"classpath":         v.classpath(details),
+		"auth_player_name":  username,
+		"version_name":      details.ID,
+		"game_directory":    v.cfg.GameDir,
+		"assets_root":       filepath.Join(v.cfg.GameDir, "assets"),
+		"assets_index_name": details.AssetIndex.ID,
+		// only used by minecraftArguments of older versions
+		"game_assets":       filepath.Join(v.cfg.GameDir, "assets"),
+		"auth_session":      "0",
+		"user_properties":   "{}",
+		"auth_uuid":         uuid,
+		"auth_access_token": "0",
+		"clientid":          "0",
+		"auth_xuid":         "0",
+		"user_type":         "legacy",
+		"version_type":      details.Type,
+	}
+}
+
+// resolveArguments evaluates rules and substitutes ${placeholders}.
+// Arguments guarded by features (demo mode, quick play...) are never enabled
+func resolveArguments(arguments []interface{}, placeholders map[string]string) []string {
+	var args []string
+	for _, arg := range arguments {
+		switch a := arg.(type) {
+		case string:
+			args = append(args, substitute(a, placeholders))
+		case map[string]interface{}:
+			if !argumentAllowed(a["rules"]) {
+				continue
+			}
+
+			switch value := a["value"].(type) {
+			case string:
+				args = append(args, substitute(value, placeholders))
+			case []interface{}:
+				for _, v := range value {
+					if s, ok := v.(string); ok {
+						args = append(args, substitute(s, placeholders))
+					}
+				}
+			}
+		}
+	}
+
+	return args
+}
+
+func argumentAllowed(rawRules interface{}) bool {
+	rules, _ := rawRules.([]interface{})
+
+	allowed := false
+	for _, raw := range rules {
+		rule, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if _, ok := rule["features"]; ok {
+			return false
+		}
+
+		if osRule, ok := rule["os"].(map[string]interface{}); ok && !osMatches(osRule) {
+			continue
+		}
+
+		allowed = rule["action"] == "allow"
+	}
+
+	return allowed
+}
+
+func osMatches(osRule map[string]interface{}) bool {
+	if name, ok := osRule["name"].(string); ok {
+		if name == "osx" {
+			name = "darwin"
+		}
+
+		if name != runtime.GOOS {
+			return false
+		}
+	}
+
+	if arch, ok := osRule["arch"].(string); ok {
+		if arch == "x86" && runtime.GOARCH != "386" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func substitute(arg string, placeholders map[string]string) string {
+	for key, value := range placeholders {
+		arg = strings.ReplaceAll(arg, "${"+key+"}", value)
+	}
+
+	return arg
+}
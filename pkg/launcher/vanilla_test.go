@@ -0,0 +1,74 @@
+package launcher
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/types"
+)
+
+func TestBuildArgs(t *testing.T) {
+	cfg := &config.Config{GameDir: "/game", Memory: "4G", Username: "player"}
+
+	tests := []struct {
+		name    string
+		details types.VersionDetails
+		// must appear in order, other arguments may be in between
+		want []string
+		// must not appear at all
+		absent []string
+	}{
+		{
+			name: "arguments of 1.13 and newer",
+			details: types.VersionDetails{
+				ID: "1.21.8", AssetIndex: types.AssetIndex{ID: "26"},
+				Arguments: types.Arguments{
+					JVM:  []interface{}{"-cp", "${classpath}"},
+					Game: []interface{}{"--username", "${auth_player_name}", "--assetIndex", "${assets_index_name}"},
+				},
+			},
+			want:   []string{"-Xmx4G", "-cp", vanillaMainClass, "--username", "player", "--assetIndex", "26"},
+			absent: []string{"-Djava.library.path=/game/natives"},
+		},
+		{
+			name: "minecraftArguments of older versions",
+			details: types.VersionDetails{
+				ID: "1.12.2", MainClass: "net.minecraft.client.main.Main", AssetIndex: types.AssetIndex{ID: "1.12"},
+				MinecraftArguments: "--username ${auth_player_name} --assetIndex ${assets_index_name} --userProperties ${user_properties}",
+			},
+			want: []string{
+				"-Xmx4G", "-Djava.library.path=/game/natives", "-cp", vanillaMainClass,
+				"--username", "player", "--assetIndex", "1.12", "--userProperties", "{}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := NewVanillaLauncher(cfg).buildArgs(&tt.details)
+
+			rest := args
+			for _, want := range tt.want {
+				i := slices.Index(rest, want)
+				if i < 0 {
+					t.Fatalf("expected %q in order, got %q", want, args)
+				}
+				rest = rest[i+1:]
+			}
+
+			for _, arg := range tt.absent {
+				if slices.Contains(args, arg) {
+					t.Errorf("unexpected %q in %q", arg, args)
+				}
+			}
+
+			for _, arg := range args {
+				if strings.Contains(arg, "${") {
+					t.Errorf("placeholder left in %q", arg)
+				}
+			}
+		})
+	}
+}
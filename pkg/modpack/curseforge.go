@@ -0,0 +1,385 @@
+package modpack
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+const (
+	DefaultCurseForgeURL = "https://api.curseforge.com"
+	curseForgeManifest   = "manifest.json"
+)
+
+// curseforge class ids, decide which folder a file goes into
+const (
+	classMods          = 6
+	classResourcePacks = 12
+	classShaderPacks   = 6552
+)
+
+const hashAlgoSHA1 = 1
+
+type CurseForgeManifest struct {
+	Minecraft struct {
+		Version    string                `json:"version"`
+		ModLoaders []CurseForgeModLoader `json:"modLoaders"`
+	} `json:"minecraft"`
+	ManifestType string              `json:"manifestType"`
+	Name         string              `json:"name"`
+	Version      string              `json:"version"`
+	Author       string              `json:"author"`
+	Files        []CurseForgeFileRef `json:"files"`
+	Overrides    string              `json:"overrides"`
+}
+
+type CurseForgeModLoader struct {
+	ID      string `json:"id"`
+	Primary bool   `json:"primary"`
+}
+
+type CurseForgeFileRef struct {
+	ProjectID int  `json:"projectID"`
+	FileID    int  `json:"fileID"`
+	Required  bool `json:"required"`
+}
+
+// ManualDownload is a file curseforge doesnt let third party launchers download,
+// player has to get it from the website and drop it into Dir
+type ManualDownload struct {
+	ProjectID int
+	FileID    int
+	FileName  string
+	Dir       string
+	URL       string
+	Reason    string
+}
+
+func (m ManualDownload) String() string {
+	name := m.FileName
+	if name == "" {
+		name = fmt.Sprintf("project %d file %d", m.ProjectID, m.FileID)
+	}
+
+	return fmt.Sprintf("%s (%s): %s", name, m.Reason, m.URL)
+}
+
+type cfFile struct {
+	ID          int    `json:"id"`
+	ModID       int    `json:"modId"`
+	FileName    string `json:"fileName"`
+	DownloadURL string `json:"downloadUrl"`
+	FileLength  int64  `json:"fileLength"`
+	Hashes      []struct {
+		Value string `json:"value"`
+		Algo  int    `json:"algo"`
+	} `json:"hashes"`
+}
+
+func (f *cfFile) sha1() string {
+	for _, h := range f.Hashes {
+		if h.Algo == hashAlgoSHA1 {
+			return h.Value
+		}
+	}
+
+	return ""
+}
+
+type cfMod struct {
+	ID      int `json:"id"`
+	ClassID int `json:"classId"`
+	Links   struct {
+		WebsiteURL string `json:"websiteUrl"`
+	} `json:"links"`
+}
+
+type CurseForge struct {
+	client     *http.Client
+	baseURL    string
+	apiKey     string
+	log        *slog.Logger
+	onProgress downloader.ProgressFunc
+}
+
+func NewCurseForge(apiKey string) *CurseForge {
+	return &CurseForge{client: http.DefaultClient, baseURL: DefaultCurseForgeURL, apiKey: apiKey, log: slog.Default()}
+}
+
+func (c *CurseForge) WithHTTPClient(client *http.Client) *CurseForge {
+	c.client = client
+	return c
+}
+
+// any api compatible with curseforge core api, e.g. a self hosted proxy
+func (c *CurseForge) WithBaseURL(baseURL string) *CurseForge {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+func (c *CurseForge) WithLogger(log *slog.Logger) *CurseForge {
+	c.log = log
+	return c
+}
+
+// WithProgress subscribes fn to progress of modpack file downloads
+func (c *CurseForge) WithProgress(fn downloader.ProgressFunc) *CurseForge {
+	c.onProgress = fn
+	return c
+}
+
+// Import installs curseforge modpack zip into a new instance at gameDir, persisting its config is up to the caller.
+// Files that cant be downloaded automatically are returned for manual download
+func (c *CurseForge) Import(packPath, gameDir string, base *config.Config) (*config.Config, []ManualDownload, error) {
+	r, err := zip.OpenReader(packPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	data, err := readZipJSON(&r.Reader, curseForgeManifest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var manifest CurseForgeManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", curseForgeManifest, err)
+	}
+
+	if manifest.ManifestType != "minecraftModpack" {
+		return nil, nil, fmt.Errorf("unsupported manifest type: %s", manifest.ManifestType)
+	}
+
+	var manual []ManualDownload
+	cfg, err := stageInstance(base, gameDir, func(cfg *config.Config) error {
+		if err := applyCurseForgeVersions(cfg, &manifest); err != nil {
+			return err
+		}
+
+		files, err := c.downloadFiles(cfg, manifest.Files)
+		if err != nil {
+			return err
+		}
+		manual = files
+
+		overrides := manifest.Overrides
+		if overrides == "" {
+			overrides = "overrides"
+		}
+
+		if err := extractOverrides(&r.Reader, overrides, cfg.GameDir); err != nil {
+			return fmt.Errorf("failed to write overrides: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// manual downloads go into the instance, not the staging dir
+	for i := range manual {
+		manual[i].Dir = filepath.Join(cfg.GameDir, manual[i].Dir)
+	}
+
+	return cfg, manual, nil
+}
+
+func applyCurseForgeVersions(cfg *config.Config, manifest *CurseForgeManifest) error {
+	cfg.Versions.Minecraft = manifest.Minecraft.Version
+	if cfg.Versions.Minecraft == "" {
+		return fmt.Errorf("modpack does not specify minecraft version")
+	}
+
+	// vanilla modpack
+	loaders := manifest.Minecraft.ModLoaders
+	if len(loaders) == 0 {
+		return nil
+	}
+
+	loader := loaders[0]
+	if len(loaders) > 1 {
+		i := slices.IndexFunc(loaders, func(l CurseForgeModLoader) bool { return l.Primary })
+		if i < 0 {
+			return fmt.Errorf("modpack lists %d mod loaders and none of them is primary", len(loaders))
+		}
+		loader = loaders[i]
+	}
+
+	// e.g. fabric-0.16.5
+	name, version, _ := strings.Cut(loader.ID, "-")
+	switch name {
+	case "fabric":
+		cfg.Versions.Loader = downloader.LoaderFabric
+		cfg.Versions.FabricLoader = version
+	case "quilt":
+		cfg.Versions.Loader = downloader.LoaderQuilt
+		cfg.Versions.QuiltLoader = version
+	case "neoforge":
+		cfg.Versions.Loader = downloader.LoaderNeoForge
+		cfg.Versions.NeoForge = version
+	default:
+		return fmt.Errorf("unsupported mod loader: %s", loader.ID)
+	}
+
+	return nil
+}
+
+func (c *CurseForge) downloadFiles(cfg *config.Config, refs []CurseForgeFileRef) ([]ManualDownload, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	// optional files are left out, like curseforge app does by default
+	refs = slices.DeleteFunc(slices.Clone(refs), func(ref CurseForgeFileRef) bool { return !ref.Required })
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	fileIDs := make([]int, 0, len(refs))
+	modIDs := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		fileIDs = append(fileIDs, ref.FileID)
+		modIDs = append(modIDs, ref.ProjectID)
+	}
+
+	var files struct {
+		Data []cfFile `json:"data"`
+	}
+	if err := c.post("/v1/mods/files", map[string]any{"fileIds": fileIDs}, &files); err != nil {
+		return nil, fmt.Errorf("failed to resolve modpack files: %v", err)
+	}
+
+	var mods struct {
+		Data []cfMod `json:"data"`
+	}
+	if err := c.post("/v1/mods", map[string]any{"modIds": modIDs}, &mods); err != nil {
+		return nil, fmt.Errorf("failed to resolve modpack projects: %v", err)
+	}
+
+	filesByID := map[int]cfFile{}
+	for _, f := range files.Data {
+		filesByID[f.ID] = f
+	}
+
+	modsByID := map[int]cfMod{}
+	for _, m := range mods.Data {
+		modsByID[m.ID] = m
+	}
+
+	d := downloader.New(cfg).WithLogger(c.log).WithProgress(c.onProgress)
+
+	var (
+		manual []ManualDownload
+		jobs   []downloader.Job
+		paths  []string
+	)
+	for _, ref := range refs {
+		mod := modsByID[ref.ProjectID]
+		dir := classDir(mod.ClassID)
+
+		website := mod.Links.WebsiteURL
+		if website == "" {
+			website = fmt.Sprintf("https://www.curseforge.com/projects/%d", ref.ProjectID)
+		}
+
+		file, ok := filesByID[ref.FileID]
+		if !ok {
+			manual = append(manual, ManualDownload{
+				ProjectID: ref.ProjectID, FileID: ref.FileID, Dir: dir,
+				URL: website, Reason: "file not found",
+			})
+			continue
+		}
+
+		if file.DownloadURL == "" {
+			manual = append(manual, ManualDownload{
+				ProjectID: ref.ProjectID, FileID: ref.FileID, FileName: file.FileName, Dir: dir,
+				URL: fmt.Sprintf("%s/files/%d", website, file.ID), Reason: "third party downloads disabled by author",
+			})
+			continue
+		}
+
+		p, err := cleanPath(path.Join(dir, file.FileName))
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, downloader.Job{
+			Name: file.FileName, URL: file.DownloadURL, Path: filepath.Join(cfg.GameDir, filepath.FromSlash(p)),
+			SHA1: file.sha1(), Size: file.FileLength, Priority: downloader.PriorityNormal,
+		})
+		paths = append(paths, p)
+	}
+
+	c.log.Info("downloading modpack files", slog.Int("count", len(jobs)))
+	if err := d.DownloadAll(downloader.PhaseModpack, jobs); err != nil {
+		return nil, fmt.Errorf("failed to download modpack files: %v", err)
+	}
+
+	tracked := make([]downloader.LockedFile, 0, len(jobs))
+	for i, job := range jobs {
+		tracked = append(tracked, downloader.LockedFile{Path: paths[i], SHA1: job.SHA1, Source: job.URL})
+	}
+
+	if err := d.Track(tracked); err != nil {
+		return nil, err
+	}
+
+	for _, m := range manual {
+		c.log.Warn("modpack file needs manual download", slog.String("file", m.String()))
+	}
+
+	return manual, nil
+}
+
+func classDir(classID int) string {
+	switch classID {
+	case classResourcePacks:
+		return "resourcepacks"
+	case classShaderPacks:
+		return "shaderpacks"
+	default:
+		return "mods"
+	}
+}
+
+func (c *CurseForge) post(endpoint string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("x-api-key", c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error status for %s: %s", endpoint, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
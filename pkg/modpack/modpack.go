@@ -0,0 +1,215 @@
+package modpack
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+)
+
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatMrpack
+	FormatCurseForge
+	FormatMultiMC
+)
+
+// DetectFormat tells modpack format by the index file inside the archive.
+// A folder can only be a multimc instance
+func DetectFormat(packPath string) (Format, error) {
+	info, err := os.Stat(packPath)
+	if err != nil {
+		return FormatUnknown, err
+	}
+
+	if info.IsDir() {
+		if _, err := findMultiMCRoot(os.DirFS(packPath)); err != nil {
+			return FormatUnknown, err
+		}
+		return FormatMultiMC, nil
+	}
+
+	r, err := zip.OpenReader(packPath)
+	if err != nil {
+		return FormatUnknown, err
+	}
+	defer r.Close()
+
+	if _, err := fs.Stat(r, mrpackIndex); err == nil {
+		return FormatMrpack, nil
+	}
+
+	if _, err := fs.Stat(r, curseForgeManifest); err == nil {
+		return FormatCurseForge, nil
+	}
+
+	if _, err := findMultiMCRoot(r); err == nil {
+		return FormatMultiMC, nil
+	}
+
+	return FormatUnknown, fmt.Errorf("%s is not a supported modpack", filepath.Base(packPath))
+}
+
+// newInstance copies player settings from base, everything version
+// related is filled in by the importer
+func newInstance(base *config.Config, gameDir string) *config.Config {
+	cfg := *base
+	cfg.GameDir = gameDir
+	cfg.Versions = config.Versions{Launcher: base.Versions.Launcher}
+
+	return &cfg
+}
+
+// stageInstance runs install in a temporary dir next to gameDir and moves it into place
+// only if it succeeds, so a failed import doesnt leave a half populated instance behind.
+// gameDir must not exist yet
+func stageInstance(base *config.Config, gameDir string, install func(cfg *config.Config) error) (*config.Config, error) {
+	if _, err := os.Stat(gameDir); err == nil {
+		return nil, fmt.Errorf("%s already exists", gameDir)
+	}
+
+	parent := filepath.Dir(gameDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(gameDir)+"-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	cfg := newInstance(base, staging)
+	if err := install(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(staging, gameDir); err != nil {
+		return nil, err
+	}
+
+	cfg.GameDir = gameDir
+	return cfg, nil
+}
+
+// cleanPath rejects paths escaping the instance dir, modpacks are untrusted input
+func cleanPath(p string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid path in modpack: %s", p)
+	}
+
+	return cleaned, nil
+}
+
+// extractOverrides writes every file under prefix/ in the archive into gameDir
+// with prefix stripped from its path. Files are streamed, overrides can be big
+func extractOverrides(r *zip.Reader, prefix, gameDir string) error {
+	for _, f := range r.File {
+		name, ok := strings.CutPrefix(f.Name, prefix+"/")
+		if !ok || f.FileInfo().IsDir() || name == "" {
+			continue
+		}
+
+		p, err := cleanPath(name)
+		if err != nil {
+			return err
+		}
+
+		if err := extractFile(f, filepath.Join(gameDir, filepath.FromSlash(p))); err != nil {
+			return fmt.Errorf("failed to extract %s: %v", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func extractFile(f *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func readZipJSON(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found in modpack", name)
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// addDir writes every file under gameDir/dir into the archive at prefix/dir
+func addDir(w *zip.Writer, gameDir, dir, prefix string, skip func(rel string) bool) error {
+	root := filepath.Join(gameDir, dir)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(gameDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if skip != nil && skip(rel) {
+			return nil
+		}
+
+		return addFile(w, p, path.Join(prefix, rel))
+	})
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+func addFile(w *zip.Writer, src, name string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,114 @@
+package modpack
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+		err  bool
+	}{
+		{path: "mods/sodium.jar", want: "mods/sodium.jar"},
+		{path: "config//sodium/../iris.properties", want: "config/iris.properties"},
+		{path: `config\iris.properties`, want: "config/iris.properties"},
+		{path: "./options.txt", want: "options.txt"},
+		{path: "", err: true},
+		{path: ".", err: true},
+		{path: "..", err: true},
+		{path: "../outside.txt", err: true},
+		{path: "mods/../../outside.txt", err: true},
+		{path: `..\outside.txt`, err: true},
+		{path: "/etc/passwd", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := cleanPath(tt.path)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExtractOverrides(t *testing.T) {
+	tests := []struct {
+		name string
+		// archive entries, name -> content, names ending with / are dirs
+		entries map[string]string
+		// files in game dir after extracting, path -> content
+		want map[string]string
+		err  bool
+	}{
+		{
+			name: "files under prefix are extracted without it",
+			entries: map[string]string{
+				"overrides/":                 "",
+				"overrides/options.txt":      "fov:90",
+				"overrides/config/iris.json": "{}",
+				"modrinth.index.json":        "{}",
+				"overridesextra/skip.txt":    "no",
+			},
+			want: map[string]string{"options.txt": "fov:90", "config/iris.json": "{}"},
+		},
+		{
+			name:    "path escaping game dir",
+			entries: map[string]string{"overrides/../evil.txt": "evil"},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := zip.NewWriter(&buf)
+			for name, content := range tt.entries {
+				f, err := w.Create(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.Write([]byte(content))
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			root := t.TempDir()
+			gameDir := filepath.Join(root, "game")
+			err = extractOverrides(r, "overrides", gameDir)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			for p, content := range tt.want {
+				data, err := os.ReadFile(filepath.Join(gameDir, filepath.FromSlash(p)))
+				if err != nil {
+					t.Errorf("%s was not extracted: %v", p, err)
+					continue
+				}
+				if string(data) != content {
+					t.Errorf("%s: expected %q, got %q", p, content, data)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(root, "evil.txt")); !os.IsNotExist(err) {
+				t.Error("file was written outside of game dir")
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package modpack
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+const mrpackIndex = "modrinth.index.json"
+
+// https://support.modrinth.com/en/articles/8802351-modrinth-modpack-format-mrpack
+type MrpackIndex struct {
+	FormatVersion int               `json:"formatVersion"`
+	Game          string            `json:"game"`
+	VersionID     string            `json:"versionId"`
+	Name          string            `json:"name"`
+	Summary       string            `json:"summary,omitempty"`
+	Files         []MrpackFile      `json:"files"`
+	Dependencies  map[string]string `json:"dependencies"`
+}
+
+type MrpackFile struct {
+	Path      string            `json:"path"`
+	Hashes    map[string]string `json:"hashes"`
+	Env       *MrpackEnv        `json:"env,omitempty"`
+	Downloads []string          `json:"downloads"`
+	FileSize  int64             `json:"fileSize"`
+}
+
+type MrpackEnv struct {
+	Client string `json:"client"`
+	Server string `json:"server"`
+}
+
+func (f *MrpackFile) clientSide() bool {
+	return f.Env == nil || f.Env.Client != "unsupported"
+}
+
+// ImportMrpack installs .mrpack into a new instance at gameDir, persisting its config is up to the caller.
+// Player settings (java, memory, username...) are copied from base, onProgress may be nil
+func ImportMrpack(packPath, gameDir string, base *config.Config, log *slog.Logger, onProgress downloader.ProgressFunc) (*config.Config, error) {
+	r, err := zip.OpenReader(packPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := readZipJSON(&r.Reader, mrpackIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var index MrpackIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", mrpackIndex, err)
+	}
+
+	if index.Game != "minecraft" {
+		return nil, fmt.Errorf("unsupported game: %s", index.Game)
+	}
+
+	return stageInstance(base, gameDir, func(cfg *config.Config) error {
+		if err := applyMrpackDependencies(cfg, index.Dependencies); err != nil {
+			return err
+		}
+
+		d := downloader.New(cfg).WithLogger(log).WithProgress(onProgress)
+		if err := downloadMrpackFiles(d, cfg.GameDir, index.Files, log); err != nil {
+			return err
+		}
+
+		// client overrides win over common ones
+		for _, prefix := range []string{"overrides", "client-overrides"} {
+			if err := extractOverrides(&r.Reader, prefix, cfg.GameDir); err != nil {
+				return fmt.Errorf("failed to write %s: %v", prefix, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// downloadMrpackFiles downloads files as one phase, files whose mirror failed are retried from the next one
+func downloadMrpackFiles(d *downloader.Downloader, gameDir string, files []MrpackFile, log *slog.Logger) error {
+	type pending struct {
+		path string
+		file MrpackFile
+	}
+
+	var left []pending
+	for _, file := range files {
+		if !file.clientSide() {
+			continue
+		}
+
+		p, err := cleanPath(file.Path)
+		if err != nil {
+			return err
+		}
+
+		if len(file.Downloads) == 0 {
+			return fmt.Errorf("%s has no download urls", p)
+		}
+
+		left = append(left, pending{path: p, file: file})
+	}
+
+	var (
+		tracked []downloader.LockedFile
+		err     error
+	)
+	// try mirrors in order
+	for mirror := 0; len(left) > 0; mirror++ {
+		var (
+			jobs  []downloader.Job
+			round []pending
+			next  []pending
+		)
+		for _, f := range left {
+			if mirror >= len(f.file.Downloads) {
+				return fmt.Errorf("failed to download %s: %v", f.path, err)
+			}
+
+			jobs = append(jobs, downloader.Job{
+				Name: path.Base(f.path), URL: f.file.Downloads[mirror],
+				Path: filepath.Join(gameDir, filepath.FromSlash(f.path)),
+				SHA1: f.file.Hashes["sha1"], Size: f.file.FileSize,
+				Priority: downloader.PriorityNormal,
+			})
+			round = append(round, f)
+		}
+
+		log.Info("downloading modpack files", slog.Int("count", len(jobs)), slog.Int("mirror", mirror))
+		if err = d.DownloadAll(downloader.PhaseModpack, jobs); err != nil {
+			log.Warn("modpack file download failed", slog.Int("mirror", mirror), slog.String("error", err.Error()))
+		}
+
+		for i, f := range round {
+			if _, statErr := os.Stat(jobs[i].Path); statErr != nil {
+				next = append(next, f)
+				continue
+			}
+
+			tracked = append(tracked, downloader.LockedFile{Path: f.path, SHA1: jobs[i].SHA1, Source: jobs[i].URL})
+		}
+		left = next
+	}
+
+	return d.Track(tracked)
+}
+
+func applyMrpackDependencies(cfg *config.Config, deps map[string]string) error {
+	cfg.Versions.Minecraft = deps["minecraft"]
+	if cfg.Versions.Minecraft == "" {
+		return fmt.Errorf("modpack does not specify minecraft version")
+	}
+
+	for dep, version := range deps {
+		switch dep {
+		case "minecraft":
+		case "fabric-loader":
+			cfg.Versions.Loader = downloader.LoaderFabric
+			cfg.Versions.FabricLoader = version
+		case "quilt-loader":
+			cfg.Versions.Loader = downloader.LoaderQuilt
+			cfg.Versions.QuiltLoader = version
+		case "neoforge":
+			cfg.Versions.Loader = downloader.LoaderNeoForge
+			cfg.Versions.NeoForge = version
+		default:
+			return fmt.Errorf("unsupported modpack dependency: %s %s", dep, version)
+		}
+	}
+
+	return nil
+}
+
+// ExportMrpack packs mods, resourcepacks & config of the instance into .mrpack.
+// Files the launcher downloaded are referenced by url, everything else goes into overrides
+func ExportMrpack(cfg *config.Config, dest, name, version string) error {
+	d := downloader.New(cfg)
+	lock, err := d.ReadLockfile()
+	if err != nil {
+		return err
+	}
+
+	index := MrpackIndex{
+		FormatVersion: 1,
+		Game:          "minecraft",
+		VersionID:     version,
+		Name:          name,
+		Files:         []MrpackFile{},
+		Dependencies: map[string]string{
+			"minecraft": cfg.Versions.Minecraft,
+		},
+	}
+	switch cfg.Versions.Loader {
+	case downloader.LoaderQuilt:
+		index.Dependencies["quilt-loader"] = cfg.Versions.QuiltLoader
+	case downloader.LoaderNeoForge:
+		index.Dependencies["neoforge"] = cfg.Versions.NeoForge
+	default:
+		if cfg.Versions.FabricLoader != "" {
+			index.Dependencies["fabric-loader"] = cfg.Versions.FabricLoader
+		}
+	}
+
+	referenced := map[string]bool{}
+	for _, locked := range lock.Files {
+		if lock.IsDisabled(locked.Path) || !exportableSource(locked.Source) {
+			continue
+		}
+
+		file, err := mrpackFile(cfg.GameDir, locked)
+		if err != nil {
+			return err
+		}
+
+		// deleted or changed since download, the latter has to be shipped as override
+		if file == nil {
+			continue
+		}
+
+		index.Files = append(index.Files, *file)
+		referenced[locked.Path] = true
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	indexWriter, err := w.Create(mrpackIndex)
+	if err != nil {
+		return err
+	}
+	if _, err := indexWriter.Write(indexData); err != nil {
+		return err
+	}
+
+	skip := func(rel string) bool {
+		return referenced[rel] || strings.HasSuffix(rel, downloader.DisabledModSuffix)
+	}
+
+	for _, dir := range []string{"mods", "resourcepacks", "config"} {
+		if err := addDir(w, cfg.GameDir, dir, "overrides", skip); err != nil {
+			return fmt.Errorf("failed to export %s: %v", dir, err)
+		}
+	}
+
+	return w.Close()
+}
+
+func mrpackFile(gameDir string, locked downloader.LockedFile) (*MrpackFile, error) {
+	f, err := os.Open(filepath.Join(gameDir, filepath.FromSlash(locked.Path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sha1Hasher, sha512Hasher := sha1.New(), sha512.New()
+	size, err := io.Copy(io.MultiWriter(sha1Hasher, sha512Hasher), f)
+	if err != nil {
+		return nil, err
+	}
+
+	if hex.EncodeToString(sha1Hasher.Sum(nil)) != locked.SHA1 {
+		return nil, nil
+	}
+
+	return &MrpackFile{
+		Path: locked.Path,
+		Hashes: map[string]string{
+			"sha1":   locked.SHA1,
+			"sha512": hex.EncodeToString(sha512Hasher.Sum(nil)),
+		},
+		Env:       &MrpackEnv{Client: "required", Server: "required"},
+		Downloads: []string{locked.Source},
+		FileSize:  size,
+	}, nil
+}
+
+// modrinth only accepts downloads from these hosts
+var mrpackHosts = []string{
+	"cdn.modrinth.com",
+	"github.com",
+	"raw.githubusercontent.com",
+	"gitlab.com",
+}
+
+func exportableSource(source string) bool {
+	rest, ok := strings.CutPrefix(source, "https://")
+	if !ok {
+		return false
+	}
+
+	host, _, _ := strings.Cut(rest, "/")
+	for _, allowed := range mrpackHosts {
+		if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,259 @@
+package modpack
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+const (
+	multiMCConfig = "instance.cfg"
+	multiMCPack   = "mmc-pack.json"
+)
+
+// component uids used by multimc & prism
+const (
+	uidMinecraft    = "net.minecraft"
+	uidFabricLoader = "net.fabricmc.fabric-loader"
+	uidQuiltLoader  = "org.quiltmc.quilt-loader"
+	uidForge        = "net.minecraftforge"
+	uidNeoForge     = "net.neoforged"
+)
+
+type multiMCPackFile struct {
+	FormatVersion int `json:"formatVersion"`
+	Components    []struct {
+		UID     string `json:"uid"`
+		Version string `json:"version"`
+	} `json:"components"`
+}
+
+// ImportMultiMC imports multimc or prism launcher instance, either a folder or its zip export.
+// Persisting config of the new instance is up to the caller
+func ImportMultiMC(instancePath, gameDir string, base *config.Config, log *slog.Logger) (*config.Config, error) {
+	info, err := os.Stat(instancePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var fsys fs.FS
+	if info.IsDir() {
+		fsys = os.DirFS(instancePath)
+	} else {
+		r, err := zip.OpenReader(instancePath)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		fsys = r
+	}
+
+	root, err := findMultiMCRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := fs.Sub(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	minecraftDir, err := findMinecraftDir(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	return stageInstance(base, gameDir, func(cfg *config.Config) error {
+		if err := applyMultiMCPack(cfg, instance); err != nil {
+			return err
+		}
+
+		if err := applyMultiMCConfig(cfg, instance); err != nil {
+			return err
+		}
+
+		log.Info("copying instance files", slog.String("from", path.Join(root, minecraftDir)), slog.String("to", gameDir))
+		if err := copyFS(instance, minecraftDir, cfg.GameDir); err != nil {
+			return fmt.Errorf("failed to copy instance files: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// zip exports wrap the instance into a folder named after it
+func findMultiMCRoot(fsys fs.FS) (string, error) {
+	if _, err := fs.Stat(fsys, multiMCConfig); err == nil {
+		return ".", nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		if _, err := fs.Stat(fsys, path.Join(e.Name(), multiMCConfig)); err == nil {
+			return e.Name(), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s not found, not a multimc instance", multiMCConfig)
+}
+
+func findMinecraftDir(instance fs.FS) (string, error) {
+	for _, dir := range []string{".minecraft", "minecraft"} {
+		if info, err := fs.Stat(instance, dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf(".minecraft folder not found in instance")
+}
+
+func applyMultiMCPack(cfg *config.Config, instance fs.FS) error {
+	data, err := fs.ReadFile(instance, multiMCPack)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", multiMCPack, err)
+	}
+
+	var pack multiMCPackFile
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", multiMCPack, err)
+	}
+
+	for _, c := range pack.Components {
+		switch c.UID {
+		case uidMinecraft:
+			cfg.Versions.Minecraft = c.Version
+		case uidFabricLoader:
+			cfg.Versions.Loader = downloader.LoaderFabric
+			cfg.Versions.FabricLoader = c.Version
+		case uidQuiltLoader:
+			cfg.Versions.Loader = downloader.LoaderQuilt
+			cfg.Versions.QuiltLoader = c.Version
+		case uidNeoForge:
+			cfg.Versions.Loader = downloader.LoaderNeoForge
+			cfg.Versions.NeoForge = c.Version
+		case uidForge:
+			return fmt.Errorf("unsupported mod loader: %s %s", c.UID, c.Version)
+		}
+	}
+
+	if cfg.Versions.Minecraft == "" {
+		return fmt.Errorf("instance does not specify minecraft version")
+	}
+
+	return nil
+}
+
+// instance.cfg is ini-like, prism puts everything under [General]
+func readMultiMCConfig(instance fs.FS) (map[string]string, error) {
+	f, err := instance.Open(multiMCConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return values, scanner.Err()
+}
+
+func applyMultiMCConfig(cfg *config.Config, instance fs.FS) error {
+	values, err := readMultiMCConfig(instance)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", multiMCConfig, err)
+	}
+
+	// settings only apply when instance overrides global ones
+	if values["OverrideMemory"] == "true" && values["MaxMemAlloc"] != "" {
+		cfg.Memory = values["MaxMemAlloc"] + "M"
+	}
+
+	if values["OverrideJavaArgs"] == "true" {
+		cfg.JvmArgs = values["JvmArgs"]
+	}
+
+	// prism stores path to the binary, we want the folder with it.
+	// Bare "java" means the one on PATH, our default does the same
+	if javaPath := values["JavaPath"]; values["OverrideJavaLocation"] == "true" && filepath.IsAbs(javaPath) {
+		cfg.JavaPath = filepath.Dir(javaPath)
+	}
+
+	return nil
+}
+
+func copyFS(fsys fs.FS, root, dest string) error {
+	return fs.WalkDir(fsys, root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		return copyFSFile(fsys, p, target)
+	})
+}
+
+func copyFSFile(fsys fs.FS, src, dest string) error {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
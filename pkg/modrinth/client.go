@@ -0,0 +1,90 @@
+package modrinth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultBaseURL = "https://api.modrinth.com/v2"
+	userAgent      = "tblockmc/launcher (https://tblock.wtf)"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type Client struct {
+	client  *http.Client
+	baseURL string
+	log     *slog.Logger
+}
+
+func New() *Client {
+	return &Client{client: http.DefaultClient, baseURL: DefaultBaseURL, log: slog.Default()}
+}
+
+func (c *Client) WithHTTPClient(client *http.Client) *Client {
+	c.client = client
+	return c
+}
+
+// e.g. http://127.0.0.1:8080/v2 for a local stand-in server
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+	return c
+}
+
+func (c *Client) WithLogger(log *slog.Logger) *Client {
+	c.log = log
+	return c
+}
+
+func (c *Client) get(endpoint string, query url.Values, out any) error {
+	return c.do(http.MethodGet, endpoint, query, nil, out)
+}
+
+func (c *Client) do(method, endpoint string, query url.Values, body io.Reader, out any) error {
+	reqURL := c.baseURL + endpoint
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %v", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", endpoint, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error status for %s: %s", endpoint, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %v", endpoint, err)
+	}
+
+	return nil
+}
+
+func jsonList(values ...string) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
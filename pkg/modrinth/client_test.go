@@ -0,0 +1,160 @@
+package modrinth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// standIn serves versions of projects the way modrinth does, filters included
+func standIn(t *testing.T, projects map[string][]Version) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/project/{id}/version", func(w http.ResponseWriter, r *http.Request) {
+		versions, ok := projects[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		var gameVersions, loaders []string
+		if raw := r.URL.Query().Get("game_versions"); raw != "" {
+			json.Unmarshal([]byte(raw), &gameVersions)
+		}
+		if raw := r.URL.Query().Get("loaders"); raw != "" {
+			json.Unmarshal([]byte(raw), &loaders)
+		}
+
+		filtered := []Version{}
+		for _, v := range versions {
+			if (len(gameVersions) == 0 || contains(v.GameVersions, gameVersions[0])) &&
+				(len(loaders) == 0 || contains(v.Loaders, loaders[0])) {
+				filtered = append(filtered, v)
+			}
+		}
+
+		json.NewEncoder(w).Encode(filtered)
+	})
+	mux.HandleFunc("/v2/version/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, versions := range projects {
+			for _, v := range versions {
+				if v.ID == r.PathValue("id") {
+					json.NewEncoder(w).Encode(v)
+					return
+				}
+			}
+		}
+
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return New().WithHTTPClient(server.Client()).WithBaseURL(server.URL + "/v2")
+}
+
+func version(project, id, versionType string, published int, deps ...Dependency) Version {
+	return Version{
+		ID: id, ProjectID: project, VersionNumber: id, VersionType: versionType,
+		GameVersions: []string{"1.21.8"}, Loaders: []string{"fabric"},
+		DatePublished: time.Date(2025, 1, published, 0, 0, 0, 0, time.UTC),
+		Files:         []File{{Filename: id + ".jar", Primary: true, Hashes: Hashes{SHA1: id}}},
+		Dependencies:  deps,
+	}
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	c := standIn(t, map[string][]Version{
+		"sodium": {
+			version("sodium", "beta", "beta", 3),
+			version("sodium", "new", "release", 2),
+			version("sodium", "old", "release", 1),
+		},
+	})
+
+	tests := []struct {
+		name        string
+		gameVersion string
+		loader      string
+		want        string
+		err         error
+	}{
+		{name: "release preferred over newer beta", gameVersion: "1.21.8", loader: "fabric", want: "new"},
+		{name: "empty filters match any", want: "new"},
+		{name: "empty game version", loader: "fabric", want: "new"},
+		{name: "incompatible loader", gameVersion: "1.21.8", loader: "quilt", err: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := c.GetLatestVersion("sodium", tt.gameVersion, tt.loader)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v.ID != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, v.ID)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	c := standIn(t, map[string][]Version{
+		"iris": {version("iris", "iris-1", "release", 1,
+			Dependency{ProjectID: "sodium", DependencyType: Required},
+			Dependency{ProjectID: "optifine", DependencyType: Incompatible},
+		)},
+		"sodium": {version("sodium", "sodium-1", "release", 1,
+			Dependency{VersionID: "api-1", DependencyType: Required},
+		)},
+		"api": {
+			version("api", "api-2", "release", 2),
+			version("api", "api-1", "release", 1),
+		},
+		"optifine": {version("optifine", "optifine-1", "release", 1)},
+	})
+
+	res, err := c.Resolve([]string{"iris"}, "1.21.8", "fabric")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, v := range res.Versions {
+		ids = append(ids, v.ID)
+	}
+
+	// pinned dependency wins over the latest version
+	if got := strings.Join(ids, ","); got != "iris-1,sodium-1,api-1" {
+		t.Errorf("unexpected resolution %s", got)
+	}
+	if len(res.Incompatible) != 0 {
+		t.Errorf("unexpected conflicts %v", res.Incompatible)
+	}
+
+	res, err = c.Resolve([]string{"iris", "optifine"}, "1.21.8", "fabric")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Incompatible) != 1 || res.Incompatible[0].Incompatible != "optifine" {
+		t.Errorf("expected iris to conflict with optifine, got %v", res.Incompatible)
+	}
+
+	if _, err := c.Resolve([]string{"missing"}, "1.21.8", "fabric"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package modrinth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+const hashAlgorithm = "sha1"
+
+type hashesRequest struct {
+	Hashes       []string `json:"hashes"`
+	Algorithm    string   `json:"algorithm"`
+	Loaders      []string `json:"loaders,omitempty"`
+	GameVersions []string `json:"game_versions,omitempty"`
+}
+
+// GetVersionsFromHashes maps sha1 file hashes to versions they belong to,
+// unknown hashes are left out
+func (c *Client) GetVersionsFromHashes(hashes []string) (map[string]Version, error) {
+	return c.postHashes("/version_files", hashesRequest{Hashes: hashes, Algorithm: hashAlgorithm})
+}
+
+// GetLatestVersionsFromHashes maps sha1 file hashes to the newest version
+// of the same project compatible with gameVersion and loader
+func (c *Client) GetLatestVersionsFromHashes(hashes []string, gameVersion, loader string) (map[string]Version, error) {
+	return c.postHashes("/version_files/update", hashesRequest{
+		Hashes: hashes, Algorithm: hashAlgorithm,
+		Loaders: []string{loader}, GameVersions: []string{gameVersion},
+	})
+}
+
+func (c *Client) postHashes(endpoint string, body hashesRequest) (map[string]Version, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[string]Version{}
+	if err := c.do(http.MethodPost, endpoint, nil, bytes.NewReader(data), &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,85 @@
+package modrinth
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// GetProject accepts either project id or slug
+func (c *Client) GetProject(idOrSlug string) (*Project, error) {
+	var project Project
+	if err := c.get("/project/"+url.PathEscape(idOrSlug), nil, &project); err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (c *Client) GetVersion(id string) (*Version, error) {
+	var version Version
+	if err := c.get("/version/"+url.PathEscape(id), nil, &version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// GetProjectVersions lists project versions, newest first.
+// Empty gameVersion or loader disables that filter
+func (c *Client) GetProjectVersions(idOrSlug, gameVersion, loader string) ([]Version, error) {
+	query := url.Values{}
+	if gameVersion != "" {
+		query.Set("game_versions", jsonList(gameVersion))
+	}
+	if loader != "" {
+		query.Set("loaders", jsonList(loader))
+	}
+
+	var versions []Version
+	if err := c.get("/project/"+url.PathEscape(idOrSlug)+"/version", query, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetLatestVersion picks the newest version compatible with gameVersion and loader,
+// releases are preferred over betas and alphas
+func (c *Client) GetLatestVersion(idOrSlug, gameVersion, loader string) (*Version, error) {
+	versions, err := c.GetProjectVersions(idOrSlug, gameVersion, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *Version
+	for i := range versions {
+		v := &versions[i]
+		if !v.supports(gameVersion, loader) {
+			continue
+		}
+
+		if latest == nil || newer(v, latest) {
+			latest = v
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no version of %s for %s %s: %w", idOrSlug, loader, gameVersion, ErrNotFound)
+	}
+
+	return latest, nil
+}
+
+var versionTypeRank = map[string]int{
+	"release": 2,
+	"beta":    1,
+	"alpha":   0,
+}
+
+func newer(a, b *Version) bool {
+	if versionTypeRank[a.VersionType] != versionTypeRank[b.VersionType] {
+		return versionTypeRank[a.VersionType] > versionTypeRank[b.VersionType]
+	}
+
+	return a.DatePublished.After(b.DatePublished)
+}
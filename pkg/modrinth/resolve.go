@@ -0,0 +1,121 @@
+package modrinth
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Resolution is a flat set of versions to install, dependencies included
+type Resolution struct {
+	Versions []Version
+	// pairs of resolved projects that declare each other incompatible
+	Incompatible []Conflict
+}
+
+type Conflict struct {
+	ProjectID    string
+	Incompatible string
+	// set when only a specific version is incompatible
+	VersionNumber string
+}
+
+func (c Conflict) String() string {
+	if c.VersionNumber != "" {
+		return fmt.Sprintf("%s is incompatible with %s %s", c.ProjectID, c.Incompatible, c.VersionNumber)
+	}
+
+	return fmt.Sprintf("%s is incompatible with %s", c.ProjectID, c.Incompatible)
+}
+
+type declaredDependency struct {
+	from string
+	dep  Dependency
+}
+
+// Resolve finds the newest compatible version of each project and
+// walks their required dependencies transitively
+func (c *Client) Resolve(projects []string, gameVersion, loader string) (*Resolution, error) {
+	res := &Resolution{}
+	resolved := map[string]int{}
+
+	var incompatible []declaredDependency
+
+	var queue []*Version
+	add := func(v *Version) {
+		if _, ok := resolved[v.ProjectID]; ok {
+			return
+		}
+
+		resolved[v.ProjectID] = len(res.Versions)
+		res.Versions = append(res.Versions, *v)
+		queue = append(queue, v)
+	}
+
+	for _, p := range projects {
+		v, err := c.GetLatestVersion(p, gameVersion, loader)
+		if err != nil {
+			return nil, err
+		}
+
+		add(v)
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range v.Dependencies {
+			switch dep.DependencyType {
+			case Incompatible:
+				incompatible = append(incompatible, declaredDependency{v.ProjectID, dep})
+			case Required:
+				if _, ok := resolved[dep.ProjectID]; ok && dep.ProjectID != "" {
+					continue
+				}
+
+				depVersion, err := c.resolveDependency(dep, gameVersion, loader)
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve dependency of %s: %v", v.ProjectID, err)
+				}
+
+				c.log.Info("resolved dependency", slog.String("project", v.ProjectID),
+					slog.String("dependency", depVersion.ProjectID), slog.String("version", depVersion.VersionNumber))
+				add(depVersion)
+			}
+		}
+	}
+
+	for _, inc := range incompatible {
+		if inc.dep.ProjectID != "" {
+			if _, ok := resolved[inc.dep.ProjectID]; ok {
+				res.Incompatible = append(res.Incompatible, Conflict{
+					ProjectID: inc.from, Incompatible: inc.dep.ProjectID,
+				})
+			}
+			continue
+		}
+
+		for _, v := range res.Versions {
+			if v.ID == inc.dep.VersionID {
+				res.Incompatible = append(res.Incompatible, Conflict{
+					ProjectID: inc.from, Incompatible: v.ProjectID, VersionNumber: v.VersionNumber,
+				})
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Client) resolveDependency(dep Dependency, gameVersion, loader string) (*Version, error) {
+	// pinned version wins over "latest"
+	if dep.VersionID != "" {
+		return c.GetVersion(dep.VersionID)
+	}
+
+	if dep.ProjectID == "" {
+		return nil, fmt.Errorf("dependency %s has neither project nor version id", dep.FileName)
+	}
+
+	return c.GetLatestVersion(dep.ProjectID, gameVersion, loader)
+}
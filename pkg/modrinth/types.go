@@ -0,0 +1,89 @@
+package modrinth
+
+import "time"
+
+type DependencyType string
+
+const (
+	Required     DependencyType = "required"
+	Optional     DependencyType = "optional"
+	Incompatible DependencyType = "incompatible"
+	Embedded     DependencyType = "embedded"
+)
+
+type Project struct {
+	ID          string   `json:"id"`
+	Slug        string   `json:"slug"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	ProjectType string   `json:"project_type"`
+	ClientSide  string   `json:"client_side"`
+	ServerSide  string   `json:"server_side"`
+	IconURL     string   `json:"icon_url"`
+	Versions    []string `json:"versions"`
+}
+
+type Version struct {
+	ID            string       `json:"id"`
+	ProjectID     string       `json:"project_id"`
+	Name          string       `json:"name"`
+	VersionNumber string       `json:"version_number"`
+	Changelog     string       `json:"changelog"`
+	VersionType   string       `json:"version_type"`
+	GameVersions  []string     `json:"game_versions"`
+	Loaders       []string     `json:"loaders"`
+	DatePublished time.Time    `json:"date_published"`
+	Files         []File       `json:"files"`
+	Dependencies  []Dependency `json:"dependencies"`
+}
+
+type File struct {
+	Hashes   Hashes `json:"hashes"`
+	URL      string `json:"url"`
+	Filename string `json:"filename"`
+	Primary  bool   `json:"primary"`
+	Size     int64  `json:"size"`
+}
+
+type Hashes struct {
+	SHA1   string `json:"sha1"`
+	SHA512 string `json:"sha512"`
+}
+
+type Dependency struct {
+	VersionID      string         `json:"version_id,omitempty"`
+	ProjectID      string         `json:"project_id,omitempty"`
+	FileName       string         `json:"file_name,omitempty"`
+	DependencyType DependencyType `json:"dependency_type"`
+}
+
+// PrimaryFile returns the file marked as primary or the first one if none is
+func (v *Version) PrimaryFile() *File {
+	for i := range v.Files {
+		if v.Files[i].Primary {
+			return &v.Files[i]
+		}
+	}
+
+	if len(v.Files) > 0 {
+		return &v.Files[0]
+	}
+
+	return nil
+}
+
+// empty gameVersion or loader matches any, same as in GetProjectVersions
+func (v *Version) supports(gameVersion, loader string) bool {
+	return (gameVersion == "" || contains(v.GameVersions, gameVersion)) &&
+		(loader == "" || contains(v.Loaders, loader))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package mods
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+const fabricModJSON = "fabric.mod.json"
+
+type Mod struct {
+	// file name inside mods dir, for nested jars path inside the parent jar.
+	// Disabled mods are listed under their .jar name too
+	FileName string
+	Disabled bool
+	Metadata Metadata
+	IconData []byte
+	// jar-in-jar mods bundled under META-INF/jars
+	Nested []Mod
+	// set when the jar couldnt be read, Metadata is empty then
+	Error error
+}
+
+// All returns the mod itself followed by every nested mod, depth first
+func (m *Mod) All() []*Mod {
+	all := []*Mod{m}
+	for i := range m.Nested {
+		all = append(all, m.Nested[i].All()...)
+	}
+
+	return all
+}
+
+type Inspector struct {
+	cfg *config.Config
+	log *slog.Logger
+}
+
+func NewInspector(cfg *config.Config) *Inspector {
+	return &Inspector{cfg: cfg, log: slog.Default()}
+}
+
+func (i *Inspector) WithLogger(log *slog.Logger) *Inspector {
+	i.log = log
+	return i
+}
+
+// List inspects every jar in mods dir sorted by name, disabled ones included.
+// Jars without fabric.mod.json are skipped, broken ones are listed with Error set
+func (i *Inspector) List() ([]Mod, error) {
+	modsDir := filepath.Join(i.cfg.GameDir, "mods")
+	entries, err := os.ReadDir(modsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var mods []Mod
+	for _, e := range entries {
+		name, disabled := strings.CutSuffix(e.Name(), downloader.DisabledModSuffix)
+		if e.IsDir() || !strings.HasSuffix(name, ".jar") {
+			continue
+		}
+
+		mod, err := Inspect(filepath.Join(modsDir, e.Name()))
+		if errors.Is(err, ErrNotFabricMod) {
+			i.log.Warn("skipping jar without fabric.mod.json", slog.String("name", e.Name()))
+			continue
+		}
+		if err != nil {
+			i.log.Warn("failed to inspect mod", slog.String("name", e.Name()), slog.String("error", err.Error()))
+			mod = &Mod{Error: err}
+		}
+
+		mod.FileName = name
+		mod.Disabled = disabled
+		mods = append(mods, *mod)
+	}
+
+	sort.Slice(mods, func(a, b int) bool {
+		return strings.ToLower(mods[a].DisplayName()) < strings.ToLower(mods[b].DisplayName())
+	})
+
+	return mods, nil
+}
+
+// DisplayName falls back to the file name for jars that couldnt be read
+func (m *Mod) DisplayName() string {
+	if name := m.Metadata.DisplayName(); name != "" {
+		return name
+	}
+
+	return m.FileName
+}
+
+var ErrNotFabricMod = errors.New("fabric.mod.json not found")
+
+// Inspect reads fabric.mod.json of a jar and all of its nested jars.
+// Nested jars that cant be read are kept with Error set
+func Inspect(jarPath string) (*Mod, error) {
+	r, err := zip.OpenReader(jarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return inspectZip(&r.Reader, filepath.Base(jarPath))
+}
+
+func inspectZip(r *zip.Reader, name string) (*Mod, error) {
+	data, err := readZipFile(r, fabricModJSON)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFabricMod
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mod := &Mod{FileName: name}
+	if err := json.Unmarshal(data, &mod.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid fabric.mod.json: %v", err)
+	}
+
+	if mod.Metadata.Icon != "" {
+		mod.IconData, _ = readZipFile(r, strings.TrimPrefix(string(mod.Metadata.Icon), "/"))
+	}
+
+	for _, jar := range mod.Metadata.Jars {
+		nestedMod, err := inspectNested(r, jar.File)
+		if errors.Is(err, ErrNotFabricMod) {
+			continue
+		}
+		if err != nil {
+			nestedMod = &Mod{FileName: jar.File, Error: err}
+		}
+
+		mod.Nested = append(mod.Nested, *nestedMod)
+	}
+
+	return mod, nil
+}
+
+func inspectNested(r *zip.Reader, name string) (*Mod, error) {
+	nested, err := readZipFile(r, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nested jar %s: %v", name, err)
+	}
+
+	nestedReader, err := zip.NewReader(bytes.NewReader(nested), int64(len(nested)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open nested jar %s: %v", name, err)
+	}
+
+	return inspectZip(nestedReader, name)
+}
+
+func readZipFile(r *zip.Reader, name string) ([]byte, error) {
+	f, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
@@ -0,0 +1,96 @@
+package mods
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/modrinth"
+)
+
+// Installer adds mods from modrinth together with their required dependencies
+type Installer struct {
+	cfg    *config.Config
+	client *modrinth.Client
+	d      *downloader.Downloader
+	loader string
+	log    *slog.Logger
+}
+
+func NewInstaller(cfg *config.Config, client *modrinth.Client) *Installer {
+	return &Installer{
+		cfg: cfg, client: client, loader: DefaultLoader,
+		d: downloader.New(cfg), log: slog.Default(),
+	}
+}
+
+func (i *Installer) WithLoader(loader string) *Installer {
+	i.loader = loader
+	return i
+}
+
+func (i *Installer) WithDownloader(d *downloader.Downloader) *Installer {
+	i.d = d
+	return i
+}
+
+func (i *Installer) WithLogger(log *slog.Logger) *Installer {
+	i.log = log
+	return i
+}
+
+// Install resolves projects (ids or slugs) for configured minecraft version & loader
+// and downloads them into mods dir. Nothing is downloaded if resolved mods are incompatible.
+// Returns versions that were actually installed, jars already there are skipped
+func (i *Installer) Install(projects []string) (installed []modrinth.Version, err error) {
+	res, err := i.client.Resolve(projects, i.cfg.Versions.Minecraft, i.loader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res.Incompatible) > 0 {
+		conflicts := make([]string, 0, len(res.Incompatible))
+		for _, c := range res.Incompatible {
+			conflicts = append(conflicts, c.String())
+		}
+
+		return nil, fmt.Errorf("mods are incompatible: %s", strings.Join(conflicts, "; "))
+	}
+
+	// jars downloaded before a failure are still owned by the launcher
+	var locked []downloader.LockedFile
+	defer func() {
+		if trackErr := i.d.Track(locked); trackErr != nil && err == nil {
+			err = fmt.Errorf("failed to update lockfile: %v", trackErr)
+		}
+	}()
+
+	for _, v := range res.Versions {
+		file := v.PrimaryFile()
+		if file == nil {
+			return installed, fmt.Errorf("%s %s has no files", v.ProjectID, v.VersionNumber)
+		}
+
+		jarPath := filepath.Join(i.cfg.GameDir, "mods", file.Filename)
+		if _, err := os.Stat(jarPath); err == nil {
+			continue
+		}
+
+		i.log.Info("installing mod", slog.String("project", v.ProjectID), slog.String("version", v.VersionNumber))
+		if err := i.d.DownloadFile(file.URL, jarPath, file.Hashes.SHA1); err != nil {
+			return installed, fmt.Errorf("failed to download %s: %v", file.Filename, err)
+		}
+
+		installed = append(installed, v)
+		locked = append(locked, downloader.LockedFile{
+			Path: path.Join("mods", file.Filename), SHA1: file.Hashes.SHA1, Source: file.URL,
+		})
+	}
+
+	return installed, nil
+}
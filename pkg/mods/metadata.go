@@ -0,0 +1,137 @@
+package mods
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// Metadata is what we care about from fabric.mod.json
+// https://wiki.fabricmc.net/documentation:fabric_mod_json_spec
+type Metadata struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	ID            string       `json:"id"`
+	Version       string       `json:"version"`
+	Name          string       `json:"name"`
+	Description   string       `json:"description"`
+	Authors       []Person     `json:"authors"`
+	Icon          Icon         `json:"icon"`
+	Environment   string       `json:"environment"`
+	Provides      []string     `json:"provides"`
+	Depends       Dependencies `json:"depends"`
+	Breaks        Dependencies `json:"breaks"`
+	Jars          []NestedJar  `json:"jars"`
+}
+
+type NestedJar struct {
+	File string `json:"file"`
+}
+
+// Person is either a plain name or {"name": ..., "contact": {...}}
+type Person struct {
+	Name string `json:"name"`
+}
+
+func (p *Person) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		p.Name = name
+		return nil
+	}
+
+	type person Person
+	return json.Unmarshal(data, (*person)(p))
+}
+
+// Icon is either a single path or a map of sizes to paths,
+// the biggest one is kept in that case
+type Icon string
+
+func (i *Icon) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*i = Icon(path)
+		return nil
+	}
+
+	var sizes map[string]string
+	if err := json.Unmarshal(data, &sizes); err != nil {
+		return err
+	}
+
+	best := -1
+	for size, path := range sizes {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			continue
+		}
+
+		if n > best {
+			best = n
+			*i = Icon(path)
+		}
+	}
+
+	return nil
+}
+
+// Dependencies maps mod id to version predicates, any of which has to match.
+// fabric.mod.json allows both a single string and an array here
+type Dependencies map[string][]string
+
+func (d *Dependencies) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*d = make(Dependencies, len(raw))
+	for id, value := range raw {
+		var single string
+		if err := json.Unmarshal(value, &single); err == nil {
+			(*d)[id] = []string{single}
+			continue
+		}
+
+		var many []string
+		if err := json.Unmarshal(value, &many); err != nil {
+			return err
+		}
+		(*d)[id] = many
+	}
+
+	return nil
+}
+
+// IDs returns dependency ids in stable order
+func (d Dependencies) IDs() []string {
+	ids := make([]string, 0, len(d))
+	for id := range d {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func (m *Metadata) AuthorNames() []string {
+	names := make([]string, 0, len(m.Authors))
+	for _, a := range m.Authors {
+		names = append(names, a.Name)
+	}
+
+	return names
+}
+
+// DisplayName falls back to id when mod has no name
+func (m *Metadata) DisplayName() string {
+	if m.Name != "" {
+		return m.Name
+	}
+
+	return m.ID
+}
+
+func (m *Metadata) IsClientSide() bool {
+	return m.Environment == "" || m.Environment == "*" || m.Environment == "client"
+}
@@ -0,0 +1,228 @@
+package mods
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a loosely parsed semantic version, the same way fabric loader treats them:
+// any number of numeric components, optional pre-release, build metadata ignored
+type version struct {
+	raw        string
+	components []int
+	pre        []string
+	semantic   bool
+}
+
+func parseVersion(raw string) version {
+	v := version{raw: raw}
+
+	s, _, _ := strings.Cut(raw, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
+	}
+
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return version{raw: raw}
+		}
+
+		v.components = append(v.components, n)
+	}
+
+	v.semantic = len(v.components) > 0
+	return v
+}
+
+func (v version) component(i int) int {
+	if i < len(v.components) {
+		return v.components[i]
+	}
+
+	return 0
+}
+
+func compareVersions(a, b version) int {
+	if !a.semantic || !b.semantic {
+		return compareSegments(a.raw, b.raw)
+	}
+
+	for i := 0; i < max(len(a.components), len(b.components)); i++ {
+		if a.component(i) != b.component(i) {
+			if a.component(i) < b.component(i) {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// release is newer than any of its pre-releases
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < min(len(a.pre), len(b.pre)); i++ {
+		if c := comparePreRelease(a.pre[i], b.pre[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(a.pre) - len(b.pre)
+}
+
+// compareSegments orders versions that arent semver, e.g. 1.21.8_build.12 or r5b,
+// comparing runs of digits numerically and everything else as text
+func compareSegments(a, b string) int {
+	as, bs := segments(a), segments(b)
+	for i := 0; i < min(len(as), len(bs)); i++ {
+		if c := comparePreRelease(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(as) - len(bs)
+}
+
+// segments splits on separators and between digits and letters: 1.2b_3 is [1 2 b 3]
+func segments(raw string) []string {
+	var (
+		parts   []string
+		current strings.Builder
+	)
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+
+	for i, r := range raw {
+		if strings.ContainsRune(".-+_ ", r) {
+			flush()
+			continue
+		}
+
+		if i > 0 && current.Len() > 0 && isDigit(r) != isDigit(rune(raw[i-1])) {
+			flush()
+		}
+		current.WriteRune(r)
+	}
+	flush()
+
+	return parts
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func comparePreRelease(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return an - bn
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+// matchesAny reports whether raw satisfies at least one of predicates,
+// empty list means any version
+func matchesAny(raw string, predicates []string) bool {
+	if len(predicates) == 0 {
+		return true
+	}
+
+	v := parseVersion(raw)
+	for _, p := range predicates {
+		if matches(v, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// predicate is a space separated list of terms, all of which have to match
+func matches(v version, predicate string) bool {
+	for _, term := range strings.Fields(predicate) {
+		if !matchesTerm(v, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesTerm(v version, term string) bool {
+	if term == "*" {
+		return true
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if !strings.HasPrefix(term, op) {
+			continue
+		}
+
+		target := parseVersion(strings.TrimPrefix(term, op))
+		c := compareVersions(v, target)
+
+		switch op {
+		case ">=":
+			return c >= 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case "<":
+			return c < 0
+		case "=":
+			return c == 0
+		case "~":
+			// same major & minor
+			return c >= 0 && v.component(0) == target.component(0) && v.component(1) == target.component(1)
+		case "^":
+			// same major
+			return c >= 0 && v.component(0) == target.component(0)
+		}
+	}
+
+	if strings.ContainsAny(term, "xX*") {
+		return matchesWildcard(v, term)
+	}
+
+	return compareVersions(v, parseVersion(term)) == 0
+}
+
+// e.g. 1.21.x
+func matchesWildcard(v version, term string) bool {
+	if !v.semantic {
+		return false
+	}
+
+	for i, part := range strings.Split(term, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			return true
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil || v.component(i) != n {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,91 @@
+package mods
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.0.0", b: "1.0.0", want: 0},
+		{a: "1.0", b: "1.0.0", want: 0},
+		{a: "1.2.0", b: "1.10.0", want: -1},
+		{a: "2.0.0", b: "1.99.99", want: 1},
+		{a: "1.0.0+build.5", b: "1.0.0+build.7", want: 0},
+		{a: "1.0.0-beta.1", b: "1.0.0", want: -1},
+		{a: "1.0.0-beta.2", b: "1.0.0-beta.10", want: -1},
+		{a: "1.0.0-alpha", b: "1.0.0-beta", want: -1},
+		{a: "1.0.0-beta", b: "1.0.0-beta.1", want: -1},
+		{a: "1.0.0-1", b: "1.0.0-alpha", want: -1},
+		// not semver, compared segment by segment
+		{a: "r5b", b: "r10a", want: -1},
+		{a: "1.21.8_build.12", b: "1.21.8_build.9", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got := compareVersions(parseVersion(tt.a), parseVersion(tt.b))
+			if sign(got) != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+
+			if back := compareVersions(parseVersion(tt.b), parseVersion(tt.a)); sign(back) != -tt.want {
+				t.Errorf("comparison is not symmetric: %d", back)
+			}
+		})
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		version    string
+		predicates []string
+		want       bool
+	}{
+		{version: "1.0.0", predicates: nil, want: true},
+		{version: "1.0.0", predicates: []string{"*"}, want: true},
+		{version: "1.0.0", predicates: []string{"1.0.0"}, want: true},
+		{version: "1.0.1", predicates: []string{"1.0.0"}, want: false},
+		{version: "1.0.0", predicates: []string{"=1.0.0"}, want: true},
+		{version: "0.16.0", predicates: []string{">=0.15.0"}, want: true},
+		{version: "0.14.9", predicates: []string{">=0.15.0"}, want: false},
+		{version: "1.21.8", predicates: []string{">1.21"}, want: true},
+		{version: "1.21", predicates: []string{"<1.21.1"}, want: true},
+		{version: "1.21.1", predicates: []string{"<=1.21"}, want: false},
+		{version: "1.21.8", predicates: []string{"~1.21.2"}, want: true},
+		{version: "1.22.0", predicates: []string{"~1.21.2"}, want: false},
+		{version: "1.21.1", predicates: []string{"~1.21.2"}, want: false},
+		{version: "1.99.0", predicates: []string{"^1.2.0"}, want: true},
+		{version: "2.0.0", predicates: []string{"^1.2.0"}, want: false},
+		{version: "1.21.8", predicates: []string{"1.21.x"}, want: true},
+		{version: "1.20.4", predicates: []string{"1.21.x"}, want: false},
+		{version: "1.21.8", predicates: []string{"1.x"}, want: true},
+		// every term of a predicate has to match
+		{version: "1.21.5", predicates: []string{">=1.21 <1.21.5"}, want: false},
+		{version: "1.21.4", predicates: []string{">=1.21 <1.21.5"}, want: true},
+		// any predicate of the list is enough
+		{version: "1.20.1", predicates: []string{"1.21.x", "1.20.1"}, want: true},
+		{version: "1.0.0-beta.3", predicates: []string{">=1.0.0-beta.2"}, want: true},
+		{version: "1.0.0-beta.3", predicates: []string{">=1.0.0"}, want: false},
+		{version: "snapshot", predicates: []string{"1.21.x"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := matchesAny(tt.version, tt.predicates); got != tt.want {
+				t.Errorf("%s against %q: expected %v, got %v", tt.version, tt.predicates, tt.want, got)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,83 @@
+package mods
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+// Toggler turns mods on and off without deleting them.
+// Disabled mods are renamed to <name>.jar.disabled, like other launchers do,
+// and remembered in the lockfile so resource updates dont bring them back
+type Toggler struct {
+	cfg *config.Config
+	d   *downloader.Downloader
+}
+
+func NewToggler(cfg *config.Config) *Toggler {
+	return &Toggler{cfg: cfg, d: downloader.New(cfg)}
+}
+
+func (t *Toggler) WithDownloader(d *downloader.Downloader) *Toggler {
+	t.d = d
+	return t
+}
+
+// SetEnabled accepts jar name inside mods dir, e.g. sodium.jar
+func (t *Toggler) SetEnabled(fileName string, enabled bool) error {
+	fileName = strings.TrimSuffix(fileName, downloader.DisabledModSuffix)
+	if !strings.HasSuffix(fileName, ".jar") {
+		return fmt.Errorf("%s is not a jar", fileName)
+	}
+
+	jarPath := filepath.Join(t.cfg.GameDir, "mods", fileName)
+	disabledPath := jarPath + downloader.DisabledModSuffix
+
+	from, to := jarPath, disabledPath
+	if enabled {
+		from, to = disabledPath, jarPath
+	}
+
+	if err := os.Rename(from, to); err != nil {
+		// already in requested state
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if _, statErr := os.Stat(to); statErr != nil {
+			return err
+		}
+	}
+
+	return t.d.SetDisabled(path.Join("mods", fileName), !enabled)
+}
+
+func (t *Toggler) Enable(fileName string) error {
+	return t.SetEnabled(fileName, true)
+}
+
+func (t *Toggler) Disable(fileName string) error {
+	return t.SetEnabled(fileName, false)
+}
+
+// Disabled lists jar names of turned off mods
+func (t *Toggler) Disabled() ([]string, error) {
+	lock, err := t.d.ReadLockfile()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, p := range lock.Disabled {
+		if dir, name := path.Split(p); dir == "mods/" {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,319 @@
+package mods
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/config"
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+	"github.com/havrydotdev/tblock-launcher/pkg/modrinth"
+)
+
+const (
+	DefaultLoader = downloader.LoaderFabric
+	updatesDir    = ".mod-updates"
+	rollbackIndex = "rollback.json"
+)
+
+// old jar kept in rollback dir and the jar that replaced it in mods dir
+type rollbackEntry struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+	// lockfile entry of the old jar, if it was installed by the launcher
+	Locked *downloader.LockedFile `json:"locked,omitempty"`
+}
+
+type Update struct {
+	// file name inside mods dir
+	FileName  string
+	SHA1      string
+	Current   modrinth.Version
+	Available modrinth.Version
+}
+
+func (u Update) ChangelogURL() string {
+	return fmt.Sprintf("https://modrinth.com/mod/%s/version/%s", u.Available.ProjectID, u.Available.ID)
+}
+
+func (u Update) String() string {
+	return fmt.Sprintf("%s: %s → %s", u.FileName, u.Current.VersionNumber, u.Available.VersionNumber)
+}
+
+type Updater struct {
+	cfg    *config.Config
+	client *modrinth.Client
+	d      *downloader.Downloader
+	loader string
+	log    *slog.Logger
+}
+
+func NewUpdater(cfg *config.Config, client *modrinth.Client) *Updater {
+	return &Updater{
+		cfg: cfg, client: client, loader: DefaultLoader,
+		d: downloader.New(cfg), log: slog.Default(),
+	}
+}
+
+func (u *Updater) WithLoader(loader string) *Updater {
+	u.loader = loader
+	return u
+}
+
+func (u *Updater) WithDownloader(d *downloader.Downloader) *Updater {
+	u.d = d
+	return u
+}
+
+func (u *Updater) WithLogger(log *slog.Logger) *Updater {
+	u.log = log
+	return u
+}
+
+func (u *Updater) modsDir() string {
+	return filepath.Join(u.cfg.GameDir, "mods")
+}
+
+// CheckUpdates hashes every jar in mods dir and asks modrinth for newer
+// versions compatible with configured minecraft version & loader.
+// Jars modrinth doesnt know about are skipped
+func (u *Updater) CheckUpdates() ([]Update, error) {
+	entries, err := os.ReadDir(u.modsDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// by jar name, two jars may be identical copies of one mod
+	files := map[string]string{}
+	var (
+		names  []string
+		hashes []string
+	)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jar") {
+			continue
+		}
+
+		hash, err := downloader.FileSHA1(filepath.Join(u.modsDir(), e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(hashes, hash) {
+			hashes = append(hashes, hash)
+		}
+		files[e.Name()] = hash
+		names = append(names, e.Name())
+	}
+
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	current, err := u.client.GetVersionsFromHashes(hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up installed mods: %v", err)
+	}
+
+	latest, err := u.client.GetLatestVersionsFromHashes(hashes, u.cfg.Versions.Minecraft, u.loader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up mod updates: %v", err)
+	}
+
+	var updates []Update
+	for _, name := range names {
+		hash := files[name]
+		cur, ok := current[hash]
+		if !ok {
+			continue
+		}
+
+		available, ok := latest[hash]
+		if !ok || available.ID == cur.ID {
+			continue
+		}
+
+		updates = append(updates, Update{
+			FileName: name, SHA1: hash,
+			Current: cur, Available: available,
+		})
+	}
+
+	return updates, nil
+}
+
+// Apply downloads & verifies every update first, then swaps the jars.
+// Replaced jars are kept in rollback dir, if any swap fails all of them are reverted
+func (u *Updater) Apply(updates []Update) error {
+	stagingDir := filepath.Join(u.cfg.GameDir, updatesDir, "staging")
+	rollbackDir := filepath.Join(u.cfg.GameDir, updatesDir, "rollback")
+	defer os.RemoveAll(stagingDir)
+
+	staged := make([]string, len(updates))
+	for i, update := range updates {
+		file := update.Available.PrimaryFile()
+		if file == nil {
+			return fmt.Errorf("%s has no files", update.Available.ID)
+		}
+
+		staged[i] = filepath.Join(stagingDir, file.Filename)
+		u.log.Info("downloading mod update", slog.String("update", update.String()))
+		if err := u.d.DownloadFile(file.URL, staged[i], file.Hashes.SHA1); err != nil {
+			return fmt.Errorf("failed to download %s: %v", file.Filename, err)
+		}
+	}
+
+	// previous rollback copies are no longer relevant
+	if err := os.RemoveAll(rollbackDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(rollbackDir, 0755); err != nil {
+		return err
+	}
+
+	lock, err := u.d.ReadLockfile()
+	if err != nil {
+		return err
+	}
+
+	var swapped []rollbackEntry
+	placed := map[string]bool{}
+	for i, update := range updates {
+		entry := rollbackEntry{Old: update.FileName, New: filepath.Base(staged[i])}
+		if locked, ok := lock.Lookup(path.Join("mods", update.FileName)); ok {
+			entry.Locked = &locked
+		}
+
+		// identical copies of a mod update to the same jar, it is already in place
+		staging := staged[i]
+		if placed[entry.New] {
+			staging = ""
+		}
+
+		if err := u.swap(entry, staging, rollbackDir); err != nil {
+			if revertErr := u.revert(swapped, rollbackDir); revertErr != nil {
+				return fmt.Errorf("failed to update %s: %v, then %v", update.FileName, err, revertErr)
+			}
+			return fmt.Errorf("failed to update %s: %v", update.FileName, err)
+		}
+
+		swapped = append(swapped, entry)
+		placed[entry.New] = true
+	}
+
+	index, err := json.Marshal(swapped)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(rollbackDir, rollbackIndex), index, 0644); err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		file := update.Available.PrimaryFile()
+		err := u.d.ReplaceLocked(path.Join("mods", update.FileName), downloader.LockedFile{
+			Path: path.Join("mods", file.Filename), SHA1: file.Hashes.SHA1, Source: file.URL,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update lockfile: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// swap moves old jar to rollback dir and staged one in its place, empty staged only moves the old jar
+func (u *Updater) swap(entry rollbackEntry, staged, rollbackDir string) error {
+	oldPath := filepath.Join(u.modsDir(), entry.Old)
+	if err := os.Rename(oldPath, filepath.Join(rollbackDir, entry.Old)); err != nil {
+		return err
+	}
+
+	if staged == "" {
+		return nil
+	}
+
+	if err := os.Rename(staged, filepath.Join(u.modsDir(), entry.New)); err != nil {
+		os.Rename(filepath.Join(rollbackDir, entry.Old), oldPath)
+		return err
+	}
+
+	return nil
+}
+
+func (u *Updater) revert(entries []rollbackEntry, rollbackDir string) error {
+	var failed int
+	for _, entry := range entries {
+		newPath := filepath.Join(u.modsDir(), entry.New)
+		if err := os.Remove(newPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			u.log.Error("failed to remove updated mod", slog.String("path", newPath), slog.String("error", err.Error()))
+			failed++
+		}
+
+		err := os.Rename(filepath.Join(rollbackDir, entry.Old), filepath.Join(u.modsDir(), entry.Old))
+		if err != nil {
+			u.log.Error("failed to restore mod", slog.String("name", entry.Old), slog.String("error", err.Error()))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d mods failed to roll back", failed)
+	}
+
+	return nil
+}
+
+// CanRollback reports whether there is an Apply to undo
+func (u *Updater) CanRollback() bool {
+	_, err := os.Stat(filepath.Join(u.cfg.GameDir, updatesDir, "rollback", rollbackIndex))
+	return err == nil
+}
+
+// Rollback restores jars replaced by the last Apply
+func (u *Updater) Rollback() error {
+	rollbackDir := filepath.Join(u.cfg.GameDir, updatesDir, "rollback")
+	data, err := os.ReadFile(filepath.Join(rollbackDir, rollbackIndex))
+	if err != nil {
+		return err
+	}
+
+	var entries []rollbackEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	if err := u.revert(entries, rollbackDir); err != nil {
+		return err
+	}
+
+	restored := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Locked == nil {
+			continue
+		}
+
+		// identical copies share one entry of the new jar, the rest get their own back
+		var err error
+		if restored[entry.New] {
+			err = u.d.Track([]downloader.LockedFile{*entry.Locked})
+		} else {
+			err = u.d.ReplaceLocked(path.Join("mods", entry.New), *entry.Locked)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update lockfile: %v", err)
+		}
+		restored[entry.New] = true
+	}
+
+	return os.RemoveAll(rollbackDir)
+}
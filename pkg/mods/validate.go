@@ -0,0 +1,216 @@
+package mods
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/havrydotdev/tblock-launcher/pkg/downloader"
+)
+
+type ProblemKind int
+
+const (
+	MissingDependency ProblemKind = iota
+	WrongDependencyVersion
+	BreaksInstalled
+	DuplicateMod
+	// jar couldnt be read, its dependencies are unknown
+	BrokenMod
+)
+
+type Problem struct {
+	Kind ProblemKind
+	// mod declaring the dependency or duplicate mod id
+	Mod        string
+	Dependency string
+	Predicates []string
+	// installed version of the dependency, or jars sharing the id for duplicates
+	Found string
+}
+
+func (p Problem) String() string {
+	predicates := strings.Join(p.Predicates, " || ")
+
+	switch p.Kind {
+	case MissingDependency:
+		return fmt.Sprintf("%s requires %s %s, which is not installed", p.Mod, p.Dependency, predicates)
+	case WrongDependencyVersion:
+		return fmt.Sprintf("%s requires %s %s, but %s is installed", p.Mod, p.Dependency, predicates, p.Found)
+	case BreaksInstalled:
+		return fmt.Sprintf("%s is incompatible with %s %s (installed %s)", p.Mod, p.Dependency, predicates, p.Found)
+	case DuplicateMod:
+		return fmt.Sprintf("%s is installed more than once: %s", p.Mod, p.Found)
+	case BrokenMod:
+		return fmt.Sprintf("%s could not be read: %s", p.Mod, p.Found)
+	}
+
+	return p.Mod
+}
+
+// Environment holds versions of builtin dependencies
+type Environment struct {
+	Minecraft string
+	// fabric or quilt, both load fabric.mod.json
+	Loader        string
+	LoaderVersion string
+	Java          string
+}
+
+// Validate checks depends & breaks of every enabled client side mod against installed mods
+// and environment, the same way fabric loader does on startup
+func Validate(list []Mod, env Environment) []Problem {
+	provided := map[string]string{
+		"minecraft": env.Minecraft,
+		"java":      env.Java,
+	}
+
+	// quilt provides fabricloader for compatibility, but which version it claims
+	// depends on the loader release, so any fabricloader predicate is accepted
+	anyVersion := map[string]bool{}
+	switch env.Loader {
+	case downloader.LoaderQuilt:
+		provided["quilt_loader"] = env.LoaderVersion
+		provided["fabricloader"] = env.LoaderVersion
+		anyVersion["fabricloader"] = true
+	default:
+		provided["fabricloader"] = env.LoaderVersion
+	}
+
+	var problems []Problem
+
+	var enabled []Mod
+	for _, mod := range list {
+		if !mod.Disabled {
+			enabled = append(enabled, mod)
+		}
+	}
+	list = enabled
+
+	for i := range list {
+		for _, mod := range list[i].All() {
+			if mod.Error != nil {
+				problems = append(problems, Problem{Kind: BrokenMod, Mod: mod.FileName, Found: mod.Error.Error()})
+			}
+		}
+	}
+
+	jars := map[string][]string{}
+	for i := range list {
+		if list[i].Error != nil {
+			continue
+		}
+		id := list[i].Metadata.ID
+		jars[id] = append(jars[id], list[i].FileName)
+	}
+
+	for _, id := range sortedKeys(jars) {
+		if len(jars[id]) > 1 {
+			problems = append(problems, Problem{Kind: DuplicateMod, Mod: id, Found: strings.Join(jars[id], ", ")})
+		}
+	}
+
+	// a jar also bundled in another one at a different version: fabric loads the newest copy
+	// and silently drops the other, so the player may not run the version they installed.
+	// Same versions are deduplicated by fabric and so are nested copies of different jars
+	for i := range list {
+		top := &list[i]
+		if top.Error != nil {
+			continue
+		}
+
+		for j := range list {
+			if i == j {
+				continue
+			}
+
+			for _, nested := range list[j].All()[1:] {
+				if nested.Error != nil || nested.Metadata.ID != top.Metadata.ID || nested.Metadata.Version == top.Metadata.Version {
+					continue
+				}
+
+				loaded := top.Metadata.Version
+				if compareVersions(parseVersion(nested.Metadata.Version), parseVersion(loaded)) > 0 {
+					loaded = nested.Metadata.Version
+				}
+
+				problems = append(problems, Problem{
+					Kind: DuplicateMod, Mod: top.Metadata.ID,
+					Found: fmt.Sprintf("%s %s, %s %s bundled in %s (%s is loaded)",
+						top.FileName, top.Metadata.Version, nested.FileName, nested.Metadata.Version, list[j].FileName, loaded),
+				})
+			}
+		}
+	}
+
+	var all []*Mod
+	for i := range list {
+		for _, mod := range list[i].All() {
+			if mod.Error == nil {
+				all = append(all, mod)
+			}
+		}
+	}
+
+	// like fabric, newest version wins when a mod is bundled more than once
+	provide := func(id, v string) {
+		if current, ok := provided[id]; !ok || compareVersions(parseVersion(v), parseVersion(current)) > 0 {
+			provided[id] = v
+		}
+	}
+
+	for _, mod := range all {
+		provide(mod.Metadata.ID, mod.Metadata.Version)
+		for _, alias := range mod.Metadata.Provides {
+			provide(alias, mod.Metadata.Version)
+		}
+	}
+
+	for _, mod := range all {
+		if !mod.Metadata.IsClientSide() {
+			continue
+		}
+
+		for _, dep := range mod.Metadata.Depends.IDs() {
+			predicates := mod.Metadata.Depends[dep]
+			installed, ok := provided[dep]
+			if !ok {
+				problems = append(problems, Problem{
+					Kind: MissingDependency, Mod: mod.Metadata.ID,
+					Dependency: dep, Predicates: predicates,
+				})
+				continue
+			}
+
+			if !anyVersion[dep] && !matchesAny(installed, predicates) {
+				problems = append(problems, Problem{
+					Kind: WrongDependencyVersion, Mod: mod.Metadata.ID,
+					Dependency: dep, Predicates: predicates, Found: installed,
+				})
+			}
+		}
+
+		for _, dep := range mod.Metadata.Breaks.IDs() {
+			predicates := mod.Metadata.Breaks[dep]
+			installed, ok := provided[dep]
+			if ok && !anyVersion[dep] && matchesAny(installed, predicates) {
+				problems = append(problems, Problem{
+					Kind: BreaksInstalled, Mod: mod.Metadata.ID,
+					Dependency: dep, Predicates: predicates, Found: installed,
+				})
+			}
+		}
+	}
+
+	return problems
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
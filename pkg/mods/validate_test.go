@@ -0,0 +1,129 @@
+package mods
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func fabricMod(file, id, version string, depends Dependencies, nested ...Mod) Mod {
+	return Mod{FileName: file, Metadata: Metadata{ID: id, Version: version, Depends: depends}, Nested: nested}
+}
+
+func TestValidate(t *testing.T) {
+	env := Environment{Minecraft: "1.21.8", Loader: "fabric", LoaderVersion: "0.18.1", Java: "21"}
+
+	breaking := fabricMod("optifabric.jar", "optifabric", "1.0.0", nil)
+	breaking.Metadata.Breaks = Dependencies{"sodium": {"*"}}
+
+	serverOnly := fabricMod("server.jar", "server", "1.0.0", Dependencies{"missing": nil})
+	serverOnly.Metadata.Environment = "server"
+
+	disabled := fabricMod("old-sodium.jar", "sodium", "0.5.0", nil, fabricMod("META-INF/jars/lib.jar", "lib", "1.0.0", nil))
+	disabled.Disabled = true
+
+	tests := []struct {
+		name string
+		mods []Mod
+		env  Environment
+		want []Problem
+	}{
+		{
+			name: "satisfied dependencies",
+			mods: []Mod{
+				fabricMod("sodium.jar", "sodium", "0.6.0", Dependencies{"minecraft": {"1.21.x"}, "fabricloader": {">=0.16"}}),
+				fabricMod("iris.jar", "iris", "1.8.0", Dependencies{"sodium": {">=0.6.0"}, "java": {">=21"}}),
+			},
+		},
+		{
+			name: "missing dependency",
+			mods: []Mod{fabricMod("iris.jar", "iris", "1.8.0", Dependencies{"sodium": {"*"}})},
+			want: []Problem{{Kind: MissingDependency, Mod: "iris", Dependency: "sodium", Predicates: []string{"*"}}},
+		},
+		{
+			name: "wrong minecraft version",
+			mods: []Mod{fabricMod("old.jar", "old", "1.0.0", Dependencies{"minecraft": {"1.20.x"}})},
+			want: []Problem{{Kind: WrongDependencyVersion, Mod: "old", Dependency: "minecraft", Predicates: []string{"1.20.x"}, Found: "1.21.8"}},
+		},
+		{
+			name: "breaks installed mod",
+			mods: []Mod{breaking, fabricMod("sodium.jar", "sodium", "0.6.0", nil)},
+			want: []Problem{{Kind: BreaksInstalled, Mod: "optifabric", Dependency: "sodium", Predicates: []string{"*"}, Found: "0.6.0"}},
+		},
+		{
+			name: "dependency provided by nested jar and alias",
+			mods: []Mod{
+				fabricMod("api.jar", "fabric-api", "0.130.0", nil, fabricMod("META-INF/jars/base.jar", "fabric-api-base", "0.4.0", nil)),
+				fabricMod("mod.jar", "mod", "1.0.0", Dependencies{"fabric-api-base": {">=0.4"}}),
+			},
+		},
+		{
+			name: "server side mods are not checked",
+			mods: []Mod{serverOnly},
+		},
+		{
+			name: "disabled jar and its nested mods are ignored",
+			mods: []Mod{disabled, fabricMod("mod.jar", "mod", "1.0.0", Dependencies{"lib": nil})},
+			want: []Problem{{Kind: MissingDependency, Mod: "mod", Dependency: "lib"}},
+		},
+		{
+			name: "duplicate jars",
+			mods: []Mod{fabricMod("sodium-1.jar", "sodium", "0.5.0", nil), fabricMod("sodium-2.jar", "sodium", "0.6.0", nil)},
+			want: []Problem{{Kind: DuplicateMod, Mod: "sodium", Found: "sodium-1.jar, sodium-2.jar"}},
+		},
+		{
+			name: "jar shadowed by a newer bundled copy",
+			mods: []Mod{
+				fabricMod("iris.jar", "iris", "1.8.0", nil, fabricMod("META-INF/jars/sodium.jar", "sodium", "0.6.0", nil)),
+				fabricMod("sodium.jar", "sodium", "0.5.0", nil),
+			},
+			want: []Problem{{
+				Kind: DuplicateMod, Mod: "sodium",
+				Found: "sodium.jar 0.5.0, META-INF/jars/sodium.jar 0.6.0 bundled in iris.jar (0.6.0 is loaded)",
+			}},
+		},
+		{
+			name: "bundled copy of the same version is fine",
+			mods: []Mod{
+				fabricMod("iris.jar", "iris", "1.8.0", nil, fabricMod("META-INF/jars/sodium.jar", "sodium", "0.6.0", nil)),
+				fabricMod("sodium.jar", "sodium", "0.6.0", nil),
+			},
+		},
+		{
+			name: "newest bundled copy satisfies dependencies",
+			mods: []Mod{
+				fabricMod("a.jar", "a", "1.0.0", nil, fabricMod("META-INF/jars/lib.jar", "lib", "1.0.0", nil)),
+				fabricMod("b.jar", "b", "1.0.0", Dependencies{"lib": {">=2.0.0"}}, fabricMod("META-INF/jars/lib.jar", "lib", "2.0.0", nil)),
+			},
+		},
+		{
+			name: "broken jar",
+			mods: []Mod{{FileName: "broken.jar", Error: errors.New("zip: not a valid zip file")}},
+			want: []Problem{{Kind: BrokenMod, Mod: "broken.jar", Found: "zip: not a valid zip file"}},
+		},
+		{
+			name: "quilt accepts any fabricloader version",
+			env:  Environment{Minecraft: "1.21.8", Loader: "quilt", LoaderVersion: "0.29.2", Java: "21"},
+			mods: []Mod{fabricMod("mod.jar", "mod", "1.0.0", Dependencies{"fabricloader": {">=0.16"}, "quilt_loader": {">=0.29"}})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := env
+			if tt.env.Loader != "" {
+				e = tt.env
+			}
+
+			got := Validate(tt.mods, e)
+			if !slices.EqualFunc(got, tt.want, equalProblems) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func equalProblems(a, b Problem) bool {
+	return a.Kind == b.Kind && a.Mod == b.Mod && a.Dependency == b.Dependency &&
+		slices.Equal(a.Predicates, b.Predicates) && a.Found == b.Found
+}
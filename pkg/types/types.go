@@ -3,12 +3,14 @@ package types
 // TODO oh yeah, i dont know what half of these does
 // just types everywhere, move it all into a single package
 type Rule struct {
-	Action string  `json:"action"`
-	OS     *OSRule `json:"os,omitempty"`
+	Action   string          `json:"action"`
+	OS       *OSRule         `json:"os,omitempty"`
+	Features map[string]bool `json:"features,omitempty"`
 }
 
 type OSRule struct {
 	Name string `json:"name"`
+	Arch string `json:"arch,omitempty"`
 }
 
 type VersionManifest struct {
@@ -21,6 +23,14 @@ type LatestVersions struct {
 	Snapshot string `json:"snapshot"`
 }
 
+// version types in the manifest
+const (
+	VersionRelease  = "release"
+	VersionSnapshot = "snapshot"
+	VersionOldBeta  = "old_beta"
+	VersionOldAlpha = "old_alpha"
+)
+
 type Version struct {
 	ID          string `json:"id"`
 	Type        string `json:"type"`
@@ -30,12 +40,22 @@ type Version struct {
 }
 
 type VersionDetails struct {
-	ID           string     `json:"id"`
-	InheritsFrom string     `json:"inheritsFrom"`
-	MainClass    string     `json:"mainClass"`
-	Libraries    []Library  `json:"libraries"`
-	Downloads    Downloads  `json:"downloads"`
-	AssetIndex   AssetIndex `json:"assetIndex"`
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	InheritsFrom string    `json:"inheritsFrom"`
+	MainClass    string    `json:"mainClass"`
+	Arguments    Arguments `json:"arguments"`
+	// space separated game arguments of versions before 1.13, which have no Arguments
+	MinecraftArguments string     `json:"minecraftArguments,omitempty"`
+	Libraries          []Library  `json:"libraries"`
+	Downloads          Downloads  `json:"downloads"`
+	AssetIndex         AssetIndex `json:"assetIndex"`
+}
+
+// each argument is either a plain string or {"rules": [...], "value": string | [string]}
+type Arguments struct {
+	Game []interface{} `json:"game"`
+	JVM  []interface{} `json:"jvm"`
 }
 
 type Library struct {